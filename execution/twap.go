@@ -0,0 +1,233 @@
+package execution
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// tickSize and repegTicks bound how far the market can move away from a resting
+// child order before TWAP cancels and re-submits it at the new touch price
+const (
+	tickSize   = 0.01
+	repegTicks = 5
+)
+
+// Book is the slice of OrderBook's API that TWAP needs to work a parent order.
+// models.OrderBook satisfies this interface, kept separate here so execution
+// doesn't import models (which would import execution back for the TWAP
+// command, a cycle)
+type Book interface {
+	SubmitLimitOrder(side, ticker string, price float64, size int, accountID string) int
+	CancelOrder(id int) bool
+	BestBid() (float64, bool)
+	BestAsk() (float64, bool)
+	OrderRemainingSize(id int) (int, bool)
+}
+
+// SliceFill reports a child order fill so the server can relay progress over TCP
+type SliceFill struct {
+	Price float64 // Price the slice filled at
+	Size  int     // Size of this slice fill
+}
+
+// TWAPOrder slices a parent order into child limit orders submitted at a fixed
+// interval, re-pegging to the opposite side's best price as the market moves
+type TWAPOrder struct {
+	Side       string    // "buy" or "sell"
+	Ticker     string    // Trading ticker symbol
+	SliceSize  int       // Size of each child order
+	IntervalMs int       // Milliseconds between child order submissions
+	Deadline   time.Time // Time by which the full parent order must be worked
+	StopPrice  float64   // Reference price that halts submissions when crossed
+	HasStop    bool      // Whether StopPrice is in effect
+	AccountID  string    // Account the child orders are submitted under
+
+	book           Book
+	orderLimiter   *rate.Limiter // Same per-account limiter TRADE/FIX orders draw from, so child orders can't outrun it
+	targetQuantity int
+	activePosition int
+	childID        int
+	childSize      int
+	childRefPrice  float64
+	hasChild       bool
+
+	Fills    chan SliceFill // Slice fill events for the owning connection to report
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewTWAPOrder creates a TWAP executor for a parent order of totalSize against
+// book. orderLimiter is the account's order-submission limiter (the same one
+// TRADE and FIX orders draw from); every child order consumes from it too, so
+// a short intervalMs can't be used to submit orders faster than the account's
+// order rate limit allows.
+func NewTWAPOrder(book Book, side, ticker string, totalSize, sliceSize, intervalMs int, deadline time.Time, stopPrice float64, hasStop bool, accountID string, orderLimiter *rate.Limiter) *TWAPOrder {
+	return &TWAPOrder{
+		Side:           side,
+		Ticker:         ticker,
+		SliceSize:      sliceSize,
+		IntervalMs:     intervalMs,
+		Deadline:       deadline,
+		StopPrice:      stopPrice,
+		HasStop:        hasStop,
+		AccountID:      accountID,
+		book:           book,
+		orderLimiter:   orderLimiter,
+		targetQuantity: totalSize,
+		Fills:          make(chan SliceFill, 64),
+		stopChan:       make(chan struct{}),
+		doneChan:       make(chan struct{}),
+	}
+}
+
+// Start begins working the parent order in a separate goroutine
+func (t *TWAPOrder) Start() {
+	go t.run()
+}
+
+// Stop halts submissions and cancels any resting child order
+func (t *TWAPOrder) Stop() {
+	close(t.stopChan)
+}
+
+// Done returns a channel that is closed once the parent order is fully worked,
+// its deadline passes, or it is stopped
+func (t *TWAPOrder) Done() <-chan struct{} {
+	return t.doneChan
+}
+
+// run drives the slicing loop on a ticker until the parent is filled, its
+// deadline passes, or it is stopped
+func (t *TWAPOrder) run() {
+	ticker := time.NewTicker(time.Duration(t.IntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+	defer close(t.Fills)
+	defer close(t.doneChan)
+
+	for {
+		select {
+		case <-ticker.C:
+			t.tick()
+			if t.activePosition >= t.targetQuantity {
+				return
+			}
+			if time.Now().After(t.Deadline) {
+				return
+			}
+		case <-t.stopChan:
+			if t.hasChild {
+				t.book.CancelOrder(t.childID)
+			}
+			return
+		}
+	}
+}
+
+// tick reconciles the outstanding child order's fills, re-pegs or cancels it if
+// the market has moved, and submits the next slice if nothing is working
+func (t *TWAPOrder) tick() {
+	t.reconcileChild()
+
+	remaining := t.targetQuantity - t.activePosition
+	if remaining <= 0 {
+		return
+	}
+
+	refPrice, ok := t.referencePrice()
+	if !ok {
+		return // No liquidity on the opposite side to peg against yet
+	}
+
+	if t.HasStop && t.stopGuardTripped(refPrice) {
+		fmt.Printf("TWAP %s %s halted: market crossed stop price %.2f\n", t.Side, t.Ticker, t.StopPrice)
+		return
+	}
+
+	if t.hasChild {
+		if !t.pricesDrifted(refPrice) {
+			return // Still resting close enough to the touch
+		}
+		if stillResting, ok := t.book.OrderRemainingSize(t.childID); ok {
+			t.activePosition += t.childSize - stillResting
+		} else {
+			t.activePosition += t.childSize // Fully filled the instant before we cancelled
+		}
+		t.book.CancelOrder(t.childID)
+		t.hasChild = false
+		remaining = t.targetQuantity - t.activePosition
+		if remaining <= 0 {
+			return
+		}
+	}
+
+	if !t.orderLimiter.Allow() {
+		fmt.Printf("TWAP %s %s slice skipped: account order rate limit exceeded\n", t.Side, t.Ticker)
+		return // Try again next tick rather than submitting over the account's order rate limit
+	}
+
+	sliceQty := t.SliceSize
+	if sliceQty > remaining || time.Until(t.Deadline) <= time.Duration(t.IntervalMs)*time.Millisecond {
+		sliceQty = remaining // Last slice: take the residual to meet the deadline
+	}
+
+	id := t.book.SubmitLimitOrder(t.Side, t.Ticker, refPrice, sliceQty, t.AccountID)
+
+	t.childID = id
+	t.childSize = sliceQty
+	t.childRefPrice = refPrice
+	t.hasChild = true
+}
+
+// reconcileChild folds any fills on the outstanding child order into
+// activePosition and reports them on Fills
+func (t *TWAPOrder) reconcileChild() {
+	if !t.hasChild {
+		return
+	}
+
+	remaining, stillResting := t.book.OrderRemainingSize(t.childID)
+	if !stillResting {
+		filled := t.childSize
+		t.activePosition += filled
+		t.hasChild = false
+		t.Fills <- SliceFill{Price: t.childRefPrice, Size: filled}
+		return
+	}
+
+	if remaining < t.childSize {
+		filled := t.childSize - remaining
+		t.activePosition += filled
+		t.childSize = remaining
+		t.Fills <- SliceFill{Price: t.childRefPrice, Size: filled}
+	}
+}
+
+// referencePrice returns the opposite side's best price to peg the next child
+// order to: buys cross the best ask, sells cross the best bid
+func (t *TWAPOrder) referencePrice() (float64, bool) {
+	if t.Side == "buy" {
+		return t.book.BestAsk()
+	}
+	return t.book.BestBid()
+}
+
+// pricesDrifted reports whether the reference price has moved away from the
+// resting child order's price by more than repegTicks
+func (t *TWAPOrder) pricesDrifted(refPrice float64) bool {
+	diff := refPrice - t.childRefPrice
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > float64(repegTicks)*tickSize
+}
+
+// stopGuardTripped reports whether the market has crossed through StopPrice,
+// meaning submissions should be halted until it recovers
+func (t *TWAPOrder) stopGuardTripped(refPrice float64) bool {
+	if t.Side == "buy" {
+		return refPrice >= t.StopPrice
+	}
+	return refPrice <= t.StopPrice
+}
@@ -0,0 +1,249 @@
+// Package fix implements a small subset of the FIX 4.4 tag=value wire format:
+// SOH-delimited fields, BodyLength/CheckSum framing and the handful of message
+// types this exchange speaks (Logon, Logout, NewOrderSingle, OrderCancelRequest,
+// OrderCancelReplaceRequest, ExecutionReport, Heartbeat, TestRequest).
+package fix
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SOH is the FIX field delimiter (ASCII 0x01)
+const SOH = '\x01'
+
+// BeginString identifies this as a FIX 4.4 session
+const BeginString = "FIX.4.4"
+
+// Tag numbers for the fields this subset of FIX uses
+const (
+	TagBeginString  = 8
+	TagBodyLength   = 9
+	TagMsgType      = 35
+	TagMsgSeqNum    = 34
+	TagSenderCompID = 49
+	TagTargetCompID = 56
+	TagSendingTime  = 52
+	TagCheckSum     = 10
+	TagClOrdID      = 11
+	TagOrigClOrdID  = 41
+	TagSymbol       = 55
+	TagSide         = 54
+	TagOrderQty     = 38
+	TagPrice        = 44
+	TagOrdType      = 40
+	TagOrdStatus    = 39
+	TagExecType     = 150
+	TagOrderID      = 37
+	TagExecID       = 17
+	TagCumQty       = 14
+	TagLeavesQty    = 151
+	TagLastPx       = 31
+	TagLastQty      = 32
+	TagText         = 58
+)
+
+// MsgType values for the message types this subset of FIX supports
+const (
+	MsgTypeHeartbeat                 = "0"
+	MsgTypeTestRequest               = "1"
+	MsgTypeLogon                     = "A"
+	MsgTypeLogout                    = "5"
+	MsgTypeNewOrderSingle            = "D"
+	MsgTypeOrderCancelRequest        = "F"
+	MsgTypeOrderCancelReplaceRequest = "G"
+	MsgTypeExecutionReport           = "8"
+)
+
+// Side values as carried on tag 54
+const (
+	SideBuy  = "1"
+	SideSell = "2"
+)
+
+// OrdType values as carried on tag 40
+const (
+	OrdTypeMarket = "1"
+	OrdTypeLimit  = "2"
+)
+
+// ExecType/OrdStatus values this exchange reports on ExecutionReport messages
+const (
+	ExecTypeNew              = "0"
+	ExecTypeCanceled         = "4"
+	ExecTypeRejected         = "8"
+	ExecTypeTrade            = "F"
+	OrdStatusNew             = "0"
+	OrdStatusPartiallyFilled = "1"
+	OrdStatusFilled          = "2"
+	OrdStatusCanceled        = "4"
+	OrdStatusRejected        = "8"
+)
+
+// Field is a single tag=value pair, in the order it appeared on the wire
+type Field struct {
+	Tag   int
+	Value string
+}
+
+// Message is an ordered set of FIX fields
+type Message struct {
+	Fields []Field
+}
+
+// NewMessage creates an empty Message
+func NewMessage() *Message {
+	return &Message{}
+}
+
+// Set appends a tag=value field to the message
+func (m *Message) Set(tag int, value string) {
+	m.Fields = append(m.Fields, Field{Tag: tag, Value: value})
+}
+
+// Get returns the value of the first occurrence of tag, if present
+func (m *Message) Get(tag int) (string, bool) {
+	for _, f := range m.Fields {
+		if f.Tag == tag {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// MsgType returns the value of tag 35
+func (m *Message) MsgType() string {
+	msgType, _ := m.Get(TagMsgType)
+	return msgType
+}
+
+// readField reads a single SOH-delimited tag=value field from r
+func readField(r *bufio.Reader) (int, string, error) {
+	raw, err := r.ReadString(SOH)
+	if err != nil {
+		return 0, "", err
+	}
+	raw = strings.TrimSuffix(raw, string(rune(SOH)))
+
+	parts := strings.SplitN(raw, "=", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("fix: malformed field %q", raw)
+	}
+
+	tag, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("fix: malformed tag %q", parts[0])
+	}
+	return tag, parts[1], nil
+}
+
+// ReadMessage reads one length-prefixed FIX message off r: BeginString,
+// BodyLength, the body fields they describe, then CheckSum
+func ReadMessage(r *bufio.Reader) (*Message, error) {
+	tag, beginString, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+	if tag != TagBeginString {
+		return nil, fmt.Errorf("fix: expected BeginString(8), got tag %d", tag)
+	}
+
+	tag, rawLen, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+	if tag != TagBodyLength {
+		return nil, fmt.Errorf("fix: expected BodyLength(9), got tag %d", tag)
+	}
+	bodyLen, err := strconv.Atoi(rawLen)
+	if err != nil {
+		return nil, fmt.Errorf("fix: malformed BodyLength %q", rawLen)
+	}
+
+	body := make([]byte, bodyLen)
+	if _, err := readFull(r, body); err != nil {
+		return nil, err
+	}
+
+	msg := NewMessage()
+	msg.Set(TagBeginString, beginString)
+	msg.Set(TagBodyLength, rawLen)
+	for _, raw := range strings.Split(strings.TrimSuffix(string(body), string(rune(SOH))), string(rune(SOH))) {
+		if raw == "" {
+			continue
+		}
+		parts := strings.SplitN(raw, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("fix: malformed field %q", raw)
+		}
+		bodyTag, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("fix: malformed tag %q", parts[0])
+		}
+		msg.Set(bodyTag, parts[1])
+	}
+
+	tag, checkSum, err := readField(r)
+	if err != nil {
+		return nil, err
+	}
+	if tag != TagCheckSum {
+		return nil, fmt.Errorf("fix: expected CheckSum(10), got tag %d", tag)
+	}
+	msg.Set(TagCheckSum, checkSum)
+
+	return msg, nil
+}
+
+// readFull reads exactly len(buf) bytes from r
+func readFull(r *bufio.Reader, buf []byte) (int, error) {
+	read := 0
+	for read < len(buf) {
+		n, err := r.Read(buf[read:])
+		read += n
+		if err != nil {
+			return read, err
+		}
+	}
+	return read, nil
+}
+
+// Encode builds the wire bytes for a FIX message of the given type and body
+// fields, computing BodyLength and CheckSum
+func Encode(msgType string, seqNum int, senderCompID, targetCompID string, body []Field) []byte {
+	var sb strings.Builder
+	writeField(&sb, TagMsgType, msgType)
+	writeField(&sb, TagSenderCompID, senderCompID)
+	writeField(&sb, TagTargetCompID, targetCompID)
+	writeField(&sb, TagMsgSeqNum, strconv.Itoa(seqNum))
+	writeField(&sb, TagSendingTime, time.Now().UTC().Format("20060102-15:04:05.000"))
+	for _, f := range body {
+		writeField(&sb, f.Tag, f.Value)
+	}
+	bodyStr := sb.String()
+
+	header := fmt.Sprintf("8=%s\x019=%d\x01", BeginString, len(bodyStr))
+	checkSum := checksum(header + bodyStr)
+
+	return []byte(fmt.Sprintf("%s%s10=%03d\x01", header, bodyStr, checkSum))
+}
+
+// writeField appends a tag=value field followed by SOH to sb
+func writeField(sb *strings.Builder, tag int, value string) {
+	sb.WriteString(strconv.Itoa(tag))
+	sb.WriteByte('=')
+	sb.WriteString(value)
+	sb.WriteByte(SOH)
+}
+
+// checksum is the FIX CheckSum(10) algorithm: sum of all bytes mod 256
+func checksum(s string) int {
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		sum += int(s[i])
+	}
+	return sum % 256
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// AccountStore maps an API key to the shared secret used to verify that
+// account's LOGON handshake
+type AccountStore map[string]string
+
+// LoadAccountStore reads a config file of "apiKey secret" pairs, one per
+// line, blank lines and lines starting with # are ignored
+func LoadAccountStore(path string) (AccountStore, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	store := make(AccountStore)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		store[fields[0]] = fields[1]
+	}
+
+	return store, scanner.Err()
+}
+
+// authenticate verifies a LOGON handshake: hmacHex must be the hex-encoded
+// HMAC-SHA256 of nonce keyed by the secret registered for apiKey. It returns
+// the authenticated accountID (the apiKey itself) on success.
+func (s *Server) authenticate(apiKey, nonce, hmacHex string) (string, bool) {
+	secret, known := s.accounts[apiKey]
+	if !known {
+		return "", false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(nonce))
+	expected := mac.Sum(nil)
+
+	given, err := hex.DecodeString(hmacHex)
+	if err != nil {
+		return "", false
+	}
+
+	if !hmac.Equal(expected, given) {
+		return "", false
+	}
+
+	return apiKey, true
+}
@@ -1,69 +1,184 @@
-package models
-
-import (
-	"fmt"
-	"net"
-	"sync"
-)
-
-// Message represents a communication packet in the exchange system
-type Message struct {
-	From    string // Sender identifier
-	Payload []byte // Message content
-}
-
-// Exchange represents a messaging exchange/broker for client connections
-type Exchange struct {
-	name      string                // Name of the exchange/channel
-	clients   map[net.Conn]struct{} // Map of connected clients (using empty struct as value for minimal memory)
-	broadcast chan Message          // Channel for broadcasting messages to all clients
-	mu        sync.Mutex            // Mutex for thread-safe operations
-}
-
-// NewExchange creates and initializes a new Exchange instance
-func NewExchange(name string) *Exchange {
-	return &Exchange{
-		name:      name,                        // Set exchange name
-		clients:   make(map[net.Conn]struct{}), // Initialize clients map
-		broadcast: make(chan Message, 1024),    // Initialize buffered broadcast channel with capacity 1024
-	}
-}
-
-// Start runs the exchange's main message broadcasting loop
-func (ex *Exchange) Start() {
-	// Continuously process messages from the broadcast channel
-	for msg := range ex.broadcast {
-		ex.mu.Lock() // Lock for thread-safe access to clients map
-
-		// Iterate through all connected clients
-		for client := range ex.clients {
-			// Write formatted message to client (sender: payload)
-			_, err := client.Write([]byte(fmt.Sprintf("%s: %s\n", msg.From, string(msg.Payload))))
-			if err != nil {
-				// Handle write errors by logging, closing connection, and removing client
-				fmt.Println("write error: ", err)
-				client.Close()
-				delete(ex.clients, client)
-			}
-		}
-		ex.mu.Unlock() // Unlock after all clients have been processed
-	}
-}
-
-// Join adds a new client connection to the exchange
-func (ex *Exchange) Join(client net.Conn) {
-	ex.mu.Lock()         // Lock for thread-safe map modification
-	defer ex.mu.Unlock() // Ensure unlock happens when function returns
-
-	// Add client to the map using empty struct as value
-	ex.clients[client] = struct{}{}
-
-	// Log client connection and send welcome message
-	fmt.Printf("Client %s joined channel %s\n", client.RemoteAddr(), ex.name)
-	client.Write([]byte("Welcome to channel " + ex.name + "\n"))
-}
-
-// Broadcast queues a message to be sent to all connected clients
-func (ex *Exchange) Broadcast(msg Message) {
-	ex.broadcast <- msg // Send message to broadcast channel for processing
-}
+package models
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Market-data channel names clients can SUBSCRIBE to
+const (
+	ChannelBBO          = "bbo"          // Top-of-book quote updates
+	ChannelDepth        = "depth"        // L2 price-level depth diffs
+	ChannelTrades       = "trades"       // Trade prints
+	ChannelBookSnapshot = "bookSnapshot" // Accepted by Subscribe, but nothing is published on it; full snapshots go only to a client that sends SNAPSHOT
+)
+
+// outboxSize is the per-subscriber buffer depth for queued market-data lines.
+// A subscriber whose outbox fills (because its connection is slow or stuck)
+// has lines dropped for it alone; it never blocks publish or other subscribers.
+const outboxSize = 256
+
+// Exchange fans out market data for a single venue's OrderBook: top-of-book
+// quotes, L2 depth diffs and trade prints, each stamped with a per-exchange
+// sequence number so a subscriber can detect a gap and request a fresh
+// SNAPSHOT to resync.
+type Exchange struct {
+	name string     // Name of the exchange/venue
+	book *OrderBook // The order book this exchange publishes market data for
+
+	mu     sync.Mutex                       // Mutex guarding subs, outboxes and seq
+	subs   map[string]map[net.Conn]struct{} // Channel name -> subscribed clients
+	outbox map[net.Conn]chan string         // Client -> its buffered outbound line queue, drained by a dedicated writer goroutine
+	seq    uint64                           // Last sequence number assigned to a published message
+}
+
+// NewExchange creates an Exchange publishing market data for book
+func NewExchange(name string, book *OrderBook) *Exchange {
+	return &Exchange{
+		name: name,
+		book: book,
+		subs: map[string]map[net.Conn]struct{}{
+			ChannelBBO:          {},
+			ChannelDepth:        {},
+			ChannelTrades:       {},
+			ChannelBookSnapshot: {},
+		},
+		outbox: map[net.Conn]chan string{},
+	}
+}
+
+// Start drains the order book's trade and depth-event channels, publishing
+// each as market data until the book's channels are closed
+func (ex *Exchange) Start() {
+	go ex.pumpTrades()
+	go ex.pumpEvents()
+}
+
+// pumpTrades turns each trade fill into a trade print for ChannelTrades. It
+// subscribes its own trade channel rather than reading a shared one, so it
+// sees every trade regardless of what else (e.g. a FIX session) also
+// subscribes on the same book.
+func (ex *Exchange) pumpTrades() {
+	for trade := range ex.book.SubscribeTrades() {
+		seq := ex.nextSeq()
+		line := fmt.Sprintf("TRADE %s seq=%d price=%.2f size=%d buyId=%d sellId=%d\n",
+			trade.Ticker, seq, trade.Price, trade.Size, trade.BuyOrderID, trade.SellOrderID)
+		ex.publish(ChannelTrades, line)
+	}
+}
+
+// pumpEvents turns each depth change into a depth diff for ChannelDepth and,
+// alongside it, a refreshed top-of-book quote for ChannelBBO
+func (ex *Exchange) pumpEvents() {
+	for event := range ex.book.Events {
+		seq := ex.nextSeq()
+
+		depthLine := fmt.Sprintf("DEPTH %s seq=%d side=%s price=%.2f size=%d\n",
+			event.Ticker, seq, event.Side, event.Price, event.Size)
+		ex.publish(ChannelDepth, depthLine)
+
+		bboLine := fmt.Sprintf("BBO %s seq=%d bidPrice=%.2f bidSize=%d askPrice=%.2f askSize=%d\n",
+			event.Ticker, seq, event.BestBid, event.BestBidSize, event.BestAsk, event.BestAskSize)
+		ex.publish(ChannelBBO, bboLine)
+	}
+}
+
+// nextSeq returns the next monotonically increasing sequence number for this
+// exchange's market-data stream
+func (ex *Exchange) nextSeq() uint64 {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	ex.seq++
+	return ex.seq
+}
+
+// publish queues line for every client currently subscribed to channel. It
+// only ever enqueues onto each client's outbox; the actual net.Conn.Write
+// happens on that client's own writeLoop goroutine, so one slow or stuck
+// client can't stall publish (or the subs/outbox lock) for anyone else.
+func (ex *Exchange) publish(channel, line string) {
+	ex.mu.Lock()
+	outboxes := make([]chan string, 0, len(ex.subs[channel]))
+	for client := range ex.subs[channel] {
+		outboxes = append(outboxes, ex.outbox[client])
+	}
+	ex.mu.Unlock()
+
+	for _, out := range outboxes {
+		select {
+		case out <- line:
+		default:
+			fmt.Println("subscriber outbox full, dropping market-data line")
+		}
+	}
+}
+
+// Subscribe adds client to channel's subscriber set, reporting whether
+// channel is one this exchange publishes. The first subscription for a given
+// client starts its writeLoop goroutine.
+func (ex *Exchange) Subscribe(client net.Conn, channel string) bool {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+
+	set, ok := ex.subs[channel]
+	if !ok {
+		return false
+	}
+	set[client] = struct{}{}
+
+	if _, started := ex.outbox[client]; !started {
+		out := make(chan string, outboxSize)
+		ex.outbox[client] = out
+		go ex.writeLoop(client, out)
+	}
+	return true
+}
+
+// Unsubscribe removes client from every channel of this exchange and stops
+// its writeLoop, used when its connection closes
+func (ex *Exchange) Unsubscribe(client net.Conn) {
+	ex.mu.Lock()
+	out, started := ex.outbox[client]
+	delete(ex.outbox, client)
+	for _, set := range ex.subs {
+		delete(set, client)
+	}
+	ex.mu.Unlock()
+
+	if started {
+		close(out)
+	}
+}
+
+// writeLoop drains a single client's outbox and writes each line to its
+// connection, one client at a time, so a blocking write never holds up
+// publish or any other client's delivery. It exits once the outbox is closed
+// (via Unsubscribe) or the connection itself starts erroring.
+func (ex *Exchange) writeLoop(client net.Conn, outbox chan string) {
+	for line := range outbox {
+		if _, err := client.Write([]byte(line)); err != nil {
+			fmt.Println("write error:", err)
+			ex.Unsubscribe(client)
+			client.Close()
+			return
+		}
+	}
+}
+
+// Snapshot renders the book's full current depth for a resync, stamped with
+// the exchange's next sequence number so the subscriber can line it up
+// against the live DEPTH/BBO stream
+func (ex *Exchange) Snapshot() string {
+	seq := ex.nextSeq()
+	bids, asks := ex.book.DepthSnapshot()
+
+	out := fmt.Sprintf("SNAPSHOT %s seq=%d\n", ex.name, seq)
+	for _, level := range bids {
+		out += fmt.Sprintf("BID %.2f %d\n", level.Price, level.Size)
+	}
+	for _, level := range asks {
+		out += fmt.Sprintf("ASK %.2f %d\n", level.Price, level.Size)
+	}
+	return out
+}
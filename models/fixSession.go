@@ -0,0 +1,269 @@
+package models
+
+import (
+	"TCP-Exchange/fix"
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// senderCompID identifies this exchange on the FIX sessions it accepts
+const senderCompID = "TCP-EXCHANGE"
+
+// fixOrder tracks the FIX identifiers and original size of a live order so
+// fills can be reported back against the right ClOrdID
+type fixOrder struct {
+	ClOrdID string
+	OrigQty int
+}
+
+// fixSession holds the per-connection state of an accepted FIX session: the
+// account it was authenticated as during the plaintext LOGON that preceded
+// the PROTO FIX upgrade, its counterparty identity, outbound sequence number
+// and the orders it has placed that are still being worked by the book.
+// orders is read and written from both the session's read loop and its
+// pumpFIXFills goroutine, so all access to it must go through ordersMu.
+type fixSession struct {
+	conn         net.Conn
+	accountID    string
+	targetCompID string
+	outSeq       int
+	ordersMu     sync.Mutex
+	orders       map[int]*fixOrder // orderID -> FIX order state
+}
+
+// handleFIXSession upgrades a connection to FIX 4.4 framing and services it
+// until Logout or a read error ends the session. It replaces the plaintext
+// command loop for the lifetime of the connection. accountID is the account
+// the connection already authenticated as via a plaintext LOGON; every order
+// this session places is attributed to it and rate-limited against it.
+// readLimiter is the same per-connection message-read limiter the plaintext
+// loop enforces, carried over so it still applies after the upgrade.
+func (s *Server) handleFIXSession(exchangeName string, conn net.Conn, reader *bufio.Reader, accountID string, readLimiter *rate.Limiter) {
+	s.mu.Lock()
+	book, err := s.getOrCreateExchange(exchangeName)
+	s.mu.Unlock()
+	if err != nil {
+		fmt.Println("fix: failed to open exchange", exchangeName, err)
+		return
+	}
+
+	session := &fixSession{conn: conn, accountID: accountID, orders: make(map[int]*fixOrder)}
+
+	for {
+		msg, err := fix.ReadMessage(reader)
+		if err != nil {
+			fmt.Println("fix read error:", err)
+			return
+		}
+
+		if !readLimiter.Allow() {
+			fmt.Println("fix: connection read rate limit exceeded, dropping message from", session.targetCompID)
+			continue
+		}
+
+		switch msg.MsgType() {
+		case fix.MsgTypeLogon:
+			session.targetCompID, _ = msg.Get(fix.TagSenderCompID)
+			session.send(fix.MsgTypeLogon, nil)
+			go s.pumpFIXFills(book, session) // Start reporting fills for this session's orders
+
+		case fix.MsgTypeLogout:
+			session.send(fix.MsgTypeLogout, nil)
+			return
+
+		case fix.MsgTypeTestRequest:
+			session.send(fix.MsgTypeHeartbeat, nil)
+
+		case fix.MsgTypeNewOrderSingle:
+			s.handleFIXNewOrderSingle(book, session, msg)
+
+		case fix.MsgTypeOrderCancelRequest:
+			s.handleFIXCancelRequest(book, session, msg)
+
+		case fix.MsgTypeOrderCancelReplaceRequest:
+			s.handleFIXCancelReplaceRequest(book, session, msg)
+
+		default:
+			fmt.Println("fix: unsupported MsgType", msg.MsgType())
+		}
+	}
+}
+
+// handleFIXNewOrderSingle maps an inbound NewOrderSingle (D) onto OrderBook.AddOrder
+// and acknowledges it with a New ExecutionReport, or a Rejected one if the
+// session's account has exceeded its order-submission rate limit
+func (s *Server) handleFIXNewOrderSingle(book *OrderBook, session *fixSession, msg *fix.Message) {
+	clOrdID, _ := msg.Get(fix.TagClOrdID)
+	symbol, _ := msg.Get(fix.TagSymbol)
+	side, _ := msg.Get(fix.TagSide)
+	qtyStr, _ := msg.Get(fix.TagOrderQty)
+	priceStr, _ := msg.Get(fix.TagPrice)
+	ordType, _ := msg.Get(fix.TagOrdType)
+
+	if !s.getOrCreateOrderLimiter(session.accountID).Allow() {
+		session.send(fix.MsgTypeExecutionReport, []fix.Field{
+			{Tag: fix.TagClOrdID, Value: clOrdID},
+			{Tag: fix.TagExecType, Value: fix.ExecTypeRejected},
+			{Tag: fix.TagOrdStatus, Value: fix.OrdStatusRejected},
+			{Tag: fix.TagSymbol, Value: symbol},
+			{Tag: fix.TagText, Value: "order rate limit exceeded"},
+		})
+		return
+	}
+
+	qty, _ := strconv.Atoi(qtyStr)
+	price, _ := strconv.ParseFloat(priceStr, 64)
+
+	order := Order{
+		Price:     price,
+		Ticker:    symbol,
+		Size:      qty,
+		OrderType: fixOrderTypeToModel(ordType),
+		Side:      fixSideToModel(side),
+		AccountID: session.accountID,
+	}
+	id := book.AddOrder(order)
+	session.ordersMu.Lock()
+	session.orders[id] = &fixOrder{ClOrdID: clOrdID, OrigQty: qty}
+	session.ordersMu.Unlock()
+
+	session.send(fix.MsgTypeExecutionReport, []fix.Field{
+		{Tag: fix.TagClOrdID, Value: clOrdID},
+		{Tag: fix.TagOrderID, Value: strconv.Itoa(id)},
+		{Tag: fix.TagExecType, Value: fix.ExecTypeNew},
+		{Tag: fix.TagOrdStatus, Value: fix.OrdStatusNew},
+		{Tag: fix.TagSymbol, Value: symbol},
+		{Tag: fix.TagCumQty, Value: "0"},
+		{Tag: fix.TagLeavesQty, Value: qtyStr},
+	})
+}
+
+// handleFIXCancelRequest maps an inbound OrderCancelRequest (F) onto
+// OrderBook.CancelOrder, identifying the order via OrigClOrdID
+func (s *Server) handleFIXCancelRequest(book *OrderBook, session *fixSession, msg *fix.Message) {
+	origClOrdID, _ := msg.Get(fix.TagOrigClOrdID)
+	clOrdID, _ := msg.Get(fix.TagClOrdID)
+
+	id, ok := session.findOrderID(origClOrdID)
+	if !ok || !book.CancelOrder(id) {
+		session.send(fix.MsgTypeExecutionReport, []fix.Field{
+			{Tag: fix.TagClOrdID, Value: clOrdID},
+			{Tag: fix.TagOrigClOrdID, Value: origClOrdID},
+			{Tag: fix.TagExecType, Value: fix.ExecTypeCanceled},
+			{Tag: fix.TagOrdStatus, Value: fix.OrdStatusCanceled},
+			{Tag: fix.TagText, Value: "unknown order"},
+		})
+		return
+	}
+
+	session.ordersMu.Lock()
+	delete(session.orders, id)
+	session.ordersMu.Unlock()
+
+	session.send(fix.MsgTypeExecutionReport, []fix.Field{
+		{Tag: fix.TagClOrdID, Value: clOrdID},
+		{Tag: fix.TagOrigClOrdID, Value: origClOrdID},
+		{Tag: fix.TagOrderID, Value: strconv.Itoa(id)},
+		{Tag: fix.TagExecType, Value: fix.ExecTypeCanceled},
+		{Tag: fix.TagOrdStatus, Value: fix.OrdStatusCanceled},
+	})
+}
+
+// handleFIXCancelReplaceRequest maps an inbound OrderCancelReplaceRequest (G)
+// onto a cancel of the original order followed by a new order at the replaced
+// price/size
+func (s *Server) handleFIXCancelReplaceRequest(book *OrderBook, session *fixSession, msg *fix.Message) {
+	origClOrdID, _ := msg.Get(fix.TagOrigClOrdID)
+	if id, ok := session.findOrderID(origClOrdID); ok {
+		book.CancelOrder(id)
+		session.ordersMu.Lock()
+		delete(session.orders, id)
+		session.ordersMu.Unlock()
+	}
+
+	s.handleFIXNewOrderSingle(book, session, msg)
+}
+
+// findOrderID looks up the order id this session assigned to a ClOrdID
+func (session *fixSession) findOrderID(clOrdID string) (int, bool) {
+	session.ordersMu.Lock()
+	defer session.ordersMu.Unlock()
+
+	for id, o := range session.orders {
+		if o.ClOrdID == clOrdID {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// send encodes and writes a FIX message to the session's connection, stamping
+// it with the next outbound sequence number
+func (session *fixSession) send(msgType string, body []fix.Field) {
+	session.outSeq++
+	session.conn.Write(fix.Encode(msgType, session.outSeq, senderCompID, session.targetCompID, body))
+}
+
+// pumpFIXFills reports fills against this session's live orders as
+// ExecutionReport messages. It subscribes its own trade channel via
+// OrderBook.SubscribeTrades, so it sees every trade on the book regardless of
+// how many other FIX sessions or market-data subscribers are also watching it.
+func (s *Server) pumpFIXFills(book *OrderBook, session *fixSession) {
+	trades := book.SubscribeTrades()
+	defer book.UnsubscribeTrades(trades)
+
+	for trade := range trades {
+		for _, id := range []int{trade.BuyOrderID, trade.SellOrderID} {
+			session.ordersMu.Lock()
+			state, live := session.orders[id]
+			session.ordersMu.Unlock()
+			if !live {
+				continue
+			}
+
+			leaves, stillResting := book.OrderRemainingSize(id)
+			ordStatus := fix.OrdStatusPartiallyFilled
+			if !stillResting {
+				leaves = 0
+				ordStatus = fix.OrdStatusFilled
+				session.ordersMu.Lock()
+				delete(session.orders, id)
+				session.ordersMu.Unlock()
+			}
+
+			session.send(fix.MsgTypeExecutionReport, []fix.Field{
+				{Tag: fix.TagClOrdID, Value: state.ClOrdID},
+				{Tag: fix.TagOrderID, Value: strconv.Itoa(id)},
+				{Tag: fix.TagExecType, Value: fix.ExecTypeTrade},
+				{Tag: fix.TagOrdStatus, Value: ordStatus},
+				{Tag: fix.TagSymbol, Value: trade.Ticker},
+				{Tag: fix.TagLastPx, Value: strconv.FormatFloat(trade.Price, 'f', 2, 64)},
+				{Tag: fix.TagLastQty, Value: strconv.Itoa(trade.Size)},
+				{Tag: fix.TagCumQty, Value: strconv.Itoa(state.OrigQty - leaves)},
+				{Tag: fix.TagLeavesQty, Value: strconv.Itoa(leaves)},
+			})
+		}
+	}
+}
+
+// fixSideToModel converts a FIX Side(54) value to this exchange's side string
+func fixSideToModel(side string) string {
+	if side == fix.SideSell {
+		return "sell"
+	}
+	return "buy"
+}
+
+// fixOrderTypeToModel converts a FIX OrdType(40) value to this exchange's
+// numeric order type
+func fixOrderTypeToModel(ordType string) int {
+	if ordType == fix.OrdTypeMarket {
+		return GetType("market")
+	}
+	return GetType("limit")
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// recordType identifies what a journalRecord carries
+type recordType int
+
+const (
+	recordAddOrder recordType = iota + 1
+	recordCancelOrder
+	recordFill
+	recordSnapshot
+)
+
+// journalRecord is a single append-only journal entry. Only the fields
+// relevant to Type are populated.
+type journalRecord struct {
+	Seq      int64
+	Type     recordType
+	Order    Order        // recordAddOrder
+	OrderID  int          // recordCancelOrder
+	Trade    Trade        // recordFill
+	Snapshot bookSnapshot // recordSnapshot
+}
+
+// bookSnapshot is a point-in-time copy of an OrderBook's resting orders
+type bookSnapshot struct {
+	Bids        map[float64][]Order
+	Asks        map[float64][]Order
+	NextOrderID int
+}
+
+// Journal is an append-only, length-prefixed gob log of an OrderBook's
+// mutations, fsynced after every write so a crash loses at most the record
+// currently being written.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	seq  int64
+}
+
+// OpenJournal opens (creating if necessary) the journal file at path for
+// appending new records
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{file: file}, nil
+}
+
+// ReplayJournal reads every record previously appended to path, returning
+// them in order along with the last sequence number seen. A missing file
+// replays as zero records, not an error.
+func ReplayJournal(path string) ([]journalRecord, int64, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var records []journalRecord
+	var lastSeq int64
+	var goodOffset int64 // End of the last complete record
+
+	for {
+		var length uint32
+		if err := binary.Read(file, binary.BigEndian, &length); err != nil {
+			if err == io.EOF {
+				break
+			}
+			if err == io.ErrUnexpectedEOF {
+				return records, lastSeq, os.Truncate(path, goodOffset) // Drop a record torn by a crash mid-write
+			}
+			return nil, 0, err
+		}
+
+		raw := make([]byte, length)
+		if _, err := io.ReadFull(file, raw); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return records, lastSeq, os.Truncate(path, goodOffset) // Drop a record torn by a crash mid-write
+			}
+			return nil, 0, err
+		}
+		goodOffset += 4 + int64(length)
+
+		var rec journalRecord
+		if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&rec); err != nil {
+			return nil, 0, err
+		}
+		records = append(records, rec)
+		lastSeq = rec.Seq
+	}
+
+	return records, lastSeq, nil
+}
+
+// append writes rec to the journal, synchronously, stamping it with the next
+// sequence number
+func (j *Journal) append(rec journalRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	rec.Seq = j.seq
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(rec); err != nil {
+		return err
+	}
+
+	if err := binary.Write(j.file, binary.BigEndian, uint32(buf.Len())); err != nil {
+		return err
+	}
+	if _, err := j.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// appendAddOrder journals a newly accepted order
+func (j *Journal) appendAddOrder(order Order) error {
+	return j.append(journalRecord{Type: recordAddOrder, Order: order})
+}
+
+// appendCancel journals an order cancellation
+func (j *Journal) appendCancel(id int) error {
+	return j.append(journalRecord{Type: recordCancelOrder, OrderID: id})
+}
+
+// appendFill journals a completed trade
+func (j *Journal) appendFill(trade Trade) error {
+	return j.append(journalRecord{Type: recordFill, Trade: trade})
+}
+
+// appendSnapshot journals a full copy of the book's current resting orders
+func (j *Journal) appendSnapshot(snapshot bookSnapshot) error {
+	return j.append(journalRecord{Type: recordSnapshot, Snapshot: snapshot})
+}
+
+// Close closes the underlying journal file
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
@@ -1,200 +1,726 @@
-package models
-
-import (
-	"fmt"
-	"sort"
-	"sync"
-	"time"
-)
-
-// OrderBook represents a trading order book with bids and asks
-type OrderBook struct {
-	Bids        map[float64][]Order // Map of bid prices to their orders
-	Asks        map[float64][]Order // Map of ask prices to their orders
-	mu          sync.Mutex          // Mutex for thread-safe operations
-	nextOrderID int                 // Counter for generating unique order IDs
-	stopChan    chan struct{}       // Channel to signal stopping of matching goroutine
-}
-
-// NewOrderBook creates and initializes a new OrderBook instance
-func NewOrderBook() *OrderBook {
-	ob := &OrderBook{
-		Bids:     make(map[float64][]Order), // Initialize bids map
-		Asks:     make(map[float64][]Order), // Initialize asks map
-		stopChan: make(chan struct{}),       // Initialize stop channel
-	}
-
-	// Start the order matching process in a separate goroutine
-	go ob.startMatching()
-
-	return ob
-}
-
-// startMatching runs a continuous order matching process
-func (ob *OrderBook) startMatching() {
-	ticker := time.NewTicker(20 * time.Millisecond) // Create ticker for 20ms intervals
-	go func() {
-		for {
-			select {
-			case <-ticker.C: // Every 20ms, try to match orders
-				ob.MatchOrders()
-			case <-ob.stopChan: // When stop signal received, clean up and exit
-				ticker.Stop()
-				return
-			}
-		}
-	}()
-}
-
-// MatchOrders attempts to match bid and ask orders
-func (ob *OrderBook) MatchOrders() {
-	ob.mu.Lock()         // Lock the order book for thread safety
-	defer ob.mu.Unlock() // Ensure unlock happens when function returns
-
-	// Exit if either bids or asks are empty
-	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
-		return
-	}
-
-	matches := false // Track if any matches occurred
-	// Iterate through all bid prices
-	for bidPrice, bids := range ob.Bids {
-		// Iterate through all ask prices
-		for askPrice, asks := range ob.Asks {
-			// Check if bid price meets or exceeds ask price
-			if bidPrice >= askPrice {
-				for i := 0; i < len(bids); i++ {
-					bid := bids[i]
-
-					for j := 0; j < len(asks); j++ {
-						ask := asks[i] // Note: Should be asks[j], potential bug in original
-
-						// Process limit orders (OrderType == 1)
-						if bid.OrderType == 1 || ask.OrderType == 1 {
-							vol := min(bid.Size, ask.Size) // Find minimum tradeable volume
-
-							// Handle Fill-or-Kill (FOK) orders
-							if bid.FOK || ask.FOK {
-								// If volume doesn't match full order size, cancel FOK order
-								if vol != bid.Size && vol != ask.Size {
-									if bid.FOK {
-										bids = append(bids[:i], bids[i+1:]...)
-										i--
-									} else {
-										asks = append(asks[:j], asks[j+1:]...)
-										j--
-									}
-									continue
-								}
-							}
-
-							if vol > 0 { // If there's a match
-								matches = true
-
-								// Reduce sizes of both orders
-								bid.Size -= vol
-								ask.Size -= vol
-
-								// Log the trade
-								fmt.Printf("Fill at price: %.2f, size %d, with order id %d and %d\n",
-									askPrice, vol, bid.Id, ask.Id)
-
-								// Clean up ask if fully filled
-								if ask.Size == 0 {
-									ob.Asks[askPrice] = append(ob.Asks[askPrice][:j], ob.Asks[askPrice][j+1:]...)
-									j--
-									if len(ob.Asks[askPrice]) == 0 {
-										delete(ob.Asks, askPrice)
-									}
-								}
-
-								// Clean up bid if fully filled
-								if bid.Size == 0 {
-									bids = append(bids[:i], bids[i+1:]...)
-									ob.Bids[bidPrice] = bids
-									i--
-									if len(ob.Bids[bidPrice]) == 0 {
-										delete(ob.Bids, bidPrice)
-									}
-									break
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-		if !matches {
-			fmt.Printf("No matches found this iteration\n")
-		}
-	}
-}
-
-// StopMatching gracefully stops the order matching process
-func (ob *OrderBook) StopMatching() {
-	close(ob.stopChan) // Signal the matching goroutine to stop
-
-	ob.mu.Lock()         // Lock for thread safety
-	defer ob.mu.Unlock() // Ensure unlock on return
-
-	// Process Good-Til-Cancelled (GTC) orders in Bids
-	for price, bids := range ob.Bids {
-		newBids := []Order{}
-		for _, bid := range bids {
-			if bid.GTC { // Keep only GTC orders
-				newBids = append(newBids, bid)
-			}
-		}
-		if len(newBids) == 0 {
-			delete(ob.Bids, price) // Remove price level if no GTC orders remain
-		} else {
-			ob.Bids[price] = newBids // Update with only GTC orders
-		}
-	}
-
-	// Process GTC orders in Asks
-	for price, asks := range ob.Asks {
-		newAsks := []Order{}
-		for _, ask := range asks {
-			if ask.GTC { // Keep only GTC orders
-				newAsks = append(newAsks, ask)
-			}
-		}
-		if len(newAsks) == 0 {
-			delete(ob.Asks, price) // Remove price level if no GTC orders remain
-		} else {
-			ob.Asks[price] = newAsks // Update with only GTC orders
-		}
-	}
-}
-
-// AddOrder adds a new order to the order book
-func (ob *OrderBook) AddOrder(order Order) {
-	ob.mu.Lock()         // Lock for thread safety
-	defer ob.mu.Unlock() // Ensure unlock on return
-
-	// Assign order ID and timestamp
-	order.Id = ob.nextOrderID
-	order.Timestamp = time.Now()
-	ob.nextOrderID++
-
-	// Set order flags based on type
-	if order.OrderType == 3 {
-		order.GTC = true // Good-Til-Cancelled
-	} else if order.OrderType == 4 {
-		order.FOK = true // Fill-or-Kill
-	}
-
-	// Add order to appropriate side and sort by timestamp
-	if order.Side == "buy" {
-		ob.Bids[order.Price] = append(ob.Bids[order.Price], order)
-		sort.Slice(ob.Bids[order.Price], func(i, j int) bool {
-			return ob.Bids[order.Price][i].Timestamp.Before(ob.Bids[order.Price][j].Timestamp)
-		})
-	} else if order.Side == "sell" {
-		ob.Asks[order.Price] = append(ob.Asks[order.Price], order)
-		sort.Slice(ob.Asks[order.Price], func(i, j int) bool {
-			return ob.Asks[order.Price][i].Timestamp.Before(ob.Asks[order.Price][j].Timestamp)
-		})
-	}
-}
+package models
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Trade represents a single fill produced by the matching engine
+type Trade struct {
+	Ticker      string    // The stock or asset symbol the trade occurred on
+	Price       float64   // The price the trade was executed at (always the matched ask's price level)
+	Size        int       // The number of shares or contracts exchanged
+	BuyOrderID  int       // Id of the order that bought
+	SellOrderID int       // Id of the order that sold
+	Timestamp   time.Time // Time the trade was executed
+}
+
+// BookEvent reports a change to one side's resting depth, plus the resulting
+// top of book, so a market-data subscriber can maintain both an L2 depth view
+// and a best-bid/offer quote without re-querying the book
+type BookEvent struct {
+	Ticker      string  // The stock or asset symbol the change occurred on
+	Side        string  // Which side's level changed, "buy" or "sell"
+	Price       float64 // The price level that changed
+	Size        int     // Aggregate resting size at Price after the change, 0 if the level is now empty
+	BestBid     float64 // Current best bid price, 0 if none
+	BestBidSize int     // Aggregate size resting at BestBid, 0 if none
+	BestAsk     float64 // Current best ask price, 0 if none
+	BestAskSize int     // Aggregate size resting at BestAsk, 0 if none
+}
+
+// OrderBook represents a trading order book with bids and asks
+type OrderBook struct {
+	Bids             map[float64][]Order     // Map of bid prices to their FIFO order queues
+	Asks             map[float64][]Order     // Map of ask prices to their FIFO order queues
+	bidPrices        []float64               // Bid price levels sorted best-first (descending)
+	askPrices        []float64               // Ask price levels sorted best-first (ascending)
+	tradeSubsMu      sync.Mutex              // Mutex guarding tradeSubs
+	tradeSubs        map[chan Trade]struct{} // Registered trade subscribers, see SubscribeTrades
+	Events           chan BookEvent          // Channel of depth/quote updates emitted as the book changes
+	mu               sync.Mutex              // Mutex for thread-safe operations
+	nextOrderID      int                     // Counter for generating unique order IDs
+	stopChan         chan struct{}           // Channel to signal stopping of matching goroutine
+	journal          *Journal                // Append-only journal of mutations, nil if not journaling
+	snapshotInterval time.Duration           // How often to journal a full snapshot, 0 disables it
+	recoveredSeq     int64                   // Last sequence number replayed from the journal at startup
+	recoveredRecords int                     // Number of records replayed from the journal at startup
+}
+
+// NewOrderBook creates and initializes a new OrderBook instance with no journal
+func NewOrderBook() *OrderBook {
+	ob, _ := NewOrderBookWithJournal("", 0) // Empty path disables journaling, so this never errors
+	return ob
+}
+
+// NewOrderBookWithJournal creates an OrderBook backed by an append-only journal
+// at journalPath. If the journal already exists, it is replayed to reconstruct
+// the book before matching resumes. A blank journalPath disables journaling.
+// snapshotInterval of 0 disables periodic snapshots.
+func NewOrderBookWithJournal(journalPath string, snapshotInterval time.Duration) (*OrderBook, error) {
+	ob := &OrderBook{
+		Bids:      make(map[float64][]Order),     // Initialize bids map
+		Asks:      make(map[float64][]Order),     // Initialize asks map
+		tradeSubs: make(map[chan Trade]struct{}), // Initialize trade subscriber set
+		Events:    make(chan BookEvent, 1024),    // Initialize buffered depth/quote event channel with capacity 1024
+		stopChan:  make(chan struct{}),           // Initialize stop channel
+	}
+
+	if journalPath != "" {
+		records, lastSeq, err := ReplayJournal(journalPath)
+		if err != nil {
+			return nil, err
+		}
+		// The last snapshot already holds the effect of every record before it
+		for i := len(records) - 1; i > 0; i-- {
+			if records[i].Type == recordSnapshot {
+				records = records[i:]
+				break
+			}
+		}
+		ob.replay(records)
+		ob.recoveredSeq = lastSeq
+		ob.recoveredRecords = len(records)
+
+		journal, err := OpenJournal(journalPath)
+		if err != nil {
+			return nil, err
+		}
+		journal.seq = lastSeq
+		ob.journal = journal
+		ob.snapshotInterval = snapshotInterval
+	}
+
+	// Start the order matching process in a separate goroutine
+	go ob.startMatching()
+
+	if ob.journal != nil && ob.snapshotInterval > 0 {
+		go ob.startSnapshotting()
+	}
+
+	return ob, nil
+}
+
+// RecoveryStats reports the last sequence number and record count replayed
+// from the journal when this OrderBook was opened
+func (ob *OrderBook) RecoveryStats() (lastSeq int64, records int) {
+	return ob.recoveredSeq, ob.recoveredRecords
+}
+
+// replay reconstructs the book's state from journal records written before
+// a restart, then prunes non-GTC orders the same way StopMatching would have
+// left them: GTC orders ride out a restart, everything else doesn't.
+func (ob *OrderBook) replay(records []journalRecord) {
+	for _, rec := range records {
+		switch rec.Type {
+		case recordSnapshot:
+			ob.Bids = rec.Snapshot.Bids
+			ob.Asks = rec.Snapshot.Asks
+			ob.nextOrderID = rec.Snapshot.NextOrderID
+		case recordAddOrder:
+			ob.replayAddOrder(rec.Order)
+		case recordCancelOrder:
+			ob.replayCancelOrder(rec.OrderID)
+		case recordFill:
+			ob.replayFill(rec.Trade)
+		}
+	}
+
+	ob.bidPrices = nil
+	ob.askPrices = nil
+	for price := range ob.Bids {
+		ob.bidPrices = insertBidPrice(ob.bidPrices, price)
+	}
+	for price := range ob.Asks {
+		ob.askPrices = insertAskPrice(ob.askPrices, price)
+	}
+
+	ob.pruneNonGTC()
+}
+
+// replayAddOrder re-applies a journaled AddOrder without re-assigning an id
+// or timestamp, since both were already fixed at journaling time
+func (ob *OrderBook) replayAddOrder(order Order) {
+	if order.Side == "buy" {
+		ob.Bids[order.Price] = append(ob.Bids[order.Price], order)
+	} else if order.Side == "sell" {
+		ob.Asks[order.Price] = append(ob.Asks[order.Price], order)
+	}
+	if order.Id >= ob.nextOrderID {
+		ob.nextOrderID = order.Id + 1
+	}
+}
+
+// replayCancelOrder re-applies a journaled cancellation
+func (ob *OrderBook) replayCancelOrder(id int) {
+	for price, bids := range ob.Bids {
+		for i, bid := range bids {
+			if bid.Id == id {
+				ob.Bids[price] = append(bids[:i], bids[i+1:]...)
+				return
+			}
+		}
+	}
+	for price, asks := range ob.Asks {
+		for i, ask := range asks {
+			if ask.Id == id {
+				ob.Asks[price] = append(asks[:i], asks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// replayFill re-applies a journaled trade's effect on both resting orders
+func (ob *OrderBook) replayFill(trade Trade) {
+	ob.replayReduceOrder(trade.BuyOrderID, trade.Size)
+	ob.replayReduceOrder(trade.SellOrderID, trade.Size)
+}
+
+// replayReduceOrder shrinks a resting order's size by vol, removing it once
+// fully filled
+func (ob *OrderBook) replayReduceOrder(id, vol int) {
+	for price, bids := range ob.Bids {
+		for i, bid := range bids {
+			if bid.Id == id {
+				bid.Size -= vol
+				if bid.Size <= 0 {
+					ob.Bids[price] = append(bids[:i], bids[i+1:]...)
+				} else {
+					bids[i] = bid
+				}
+				return
+			}
+		}
+	}
+	for price, asks := range ob.Asks {
+		for i, ask := range asks {
+			if ask.Id == id {
+				ask.Size -= vol
+				if ask.Size <= 0 {
+					ob.Asks[price] = append(asks[:i], asks[i+1:]...)
+				} else {
+					asks[i] = ask
+				}
+				return
+			}
+		}
+	}
+}
+
+// startMatching runs a continuous order matching process
+func (ob *OrderBook) startMatching() {
+	ticker := time.NewTicker(20 * time.Millisecond) // Create ticker for 20ms intervals
+	go func() {
+		for {
+			select {
+			case <-ticker.C: // Every 20ms, try to match orders
+				ob.MatchOrders()
+			case <-ob.stopChan: // When stop signal received, clean up and exit
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// startSnapshotting periodically journals a full copy of the book's resting
+// orders so recovery doesn't have to replay the entire history from scratch
+func (ob *OrderBook) startSnapshotting() {
+	ticker := time.NewTicker(ob.snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ob.mu.Lock()
+			snapshot := bookSnapshot{
+				Bids:        copyOrderMap(ob.Bids),
+				Asks:        copyOrderMap(ob.Asks),
+				NextOrderID: ob.nextOrderID,
+			}
+			ob.mu.Unlock()
+
+			if err := ob.journal.appendSnapshot(snapshot); err != nil {
+				fmt.Println("journal snapshot error:", err)
+			}
+		case <-ob.stopChan:
+			return
+		}
+	}
+}
+
+// copyOrderMap deep-copies a price-level map so a journaled snapshot can't be
+// mutated by the matching goroutine after it's handed off for encoding
+func copyOrderMap(src map[float64][]Order) map[float64][]Order {
+	dst := make(map[float64][]Order, len(src))
+	for price, orders := range src {
+		dst[price] = append([]Order(nil), orders...)
+	}
+	return dst
+}
+
+// insertBidPrice inserts a new bid price level, keeping bidPrices sorted best-first (descending)
+func insertBidPrice(prices []float64, price float64) []float64 {
+	idx := sort.Search(len(prices), func(i int) bool { return prices[i] <= price })
+	if idx < len(prices) && prices[idx] == price {
+		return prices // Price level already tracked
+	}
+	prices = append(prices, 0)
+	copy(prices[idx+1:], prices[idx:])
+	prices[idx] = price
+	return prices
+}
+
+// removeBidPrice removes a bid price level from bidPrices, preserving descending order
+func removeBidPrice(prices []float64, price float64) []float64 {
+	idx := sort.Search(len(prices), func(i int) bool { return prices[i] <= price })
+	if idx < len(prices) && prices[idx] == price {
+		prices = append(prices[:idx], prices[idx+1:]...)
+	}
+	return prices
+}
+
+// insertAskPrice inserts a new ask price level, keeping askPrices sorted best-first (ascending)
+func insertAskPrice(prices []float64, price float64) []float64 {
+	idx := sort.Search(len(prices), func(i int) bool { return prices[i] >= price })
+	if idx < len(prices) && prices[idx] == price {
+		return prices // Price level already tracked
+	}
+	prices = append(prices, 0)
+	copy(prices[idx+1:], prices[idx:])
+	prices[idx] = price
+	return prices
+}
+
+// removeAskPrice removes an ask price level from askPrices, preserving ascending order
+func removeAskPrice(prices []float64, price float64) []float64 {
+	idx := sort.Search(len(prices), func(i int) bool { return prices[i] >= price })
+	if idx < len(prices) && prices[idx] == price {
+		prices = append(prices[:idx], prices[idx+1:]...)
+	}
+	return prices
+}
+
+// MatchOrders crosses the best bid against the best ask, walking price levels in
+// monotonic best-to-worst order and filling each level's FIFO queue front-to-back
+func (ob *OrderBook) MatchOrders() {
+	ob.mu.Lock()         // Lock the order book for thread safety
+	defer ob.mu.Unlock() // Ensure unlock happens when function returns
+
+	matched := false // Track if any matches occurred this tick
+
+	for len(ob.bidPrices) > 0 && len(ob.askPrices) > 0 {
+		bestBid := ob.bidPrices[0] // Highest resting bid price
+		bestAsk := ob.askPrices[0] // Lowest resting ask price
+
+		if bestBid < bestAsk {
+			break // Book no longer crosses, nothing left to match
+		}
+
+		bidQueue := ob.Bids[bestBid]
+		askQueue := ob.Asks[bestAsk]
+
+		bid := bidQueue[0] // Oldest bid at the best price (time priority)
+		ask := askQueue[0] // Oldest ask at the best price (time priority)
+
+		vol := min(bid.Size, ask.Size) // Find minimum tradeable volume
+
+		// A Fill-or-Kill order larger than the order it is matched against (the front
+		// of the opposite best level's queue) is cancelled, not partially filled, so
+		// pull it off the front of its queue and retry. Other resting liquidity is
+		// not counted, and an FOK order that doesn't cross stays resting.
+		if bid.FOK && vol != bid.Size {
+			bidQueue = bidQueue[1:]
+			ob.setBidQueue(bestBid, bidQueue)
+			ob.emitBookEvent(bid.Ticker, "buy", bestBid)
+			continue
+		}
+		if ask.FOK && vol != ask.Size {
+			askQueue = askQueue[1:]
+			ob.setAskQueue(bestAsk, askQueue)
+			ob.emitBookEvent(ask.Ticker, "sell", bestAsk)
+			continue
+		}
+
+		matched = true
+
+		// Reduce sizes of both orders
+		bid.Size -= vol
+		ask.Size -= vol
+
+		// Log the trade and emit it on the Trades channel for subscribers
+		fmt.Printf("Fill at price: %.2f, size %d, with order id %d and %d\n", bestAsk, vol, bid.Id, ask.Id)
+		trade := Trade{
+			Ticker:      ask.Ticker,
+			Price:       bestAsk,
+			Size:        vol,
+			BuyOrderID:  bid.Id,
+			SellOrderID: ask.Id,
+			Timestamp:   time.Now(),
+		}
+		if ob.journal != nil {
+			if err := ob.journal.appendFill(trade); err != nil {
+				fmt.Println("journal append error:", err)
+			}
+		}
+		ob.emitTrade(trade)
+
+		// Advance the bid queue, keeping the order resting if only partially filled
+		if bid.Size == 0 {
+			bidQueue = bidQueue[1:]
+		} else {
+			bidQueue[0] = bid
+		}
+
+		// Advance the ask queue, keeping the order resting if only partially filled
+		if ask.Size == 0 {
+			askQueue = askQueue[1:]
+		} else {
+			askQueue[0] = ask
+		}
+
+		ob.setBidQueue(bestBid, bidQueue)
+		ob.setAskQueue(bestAsk, askQueue)
+		ob.emitBookEvent(bid.Ticker, "buy", bestBid)
+		ob.emitBookEvent(ask.Ticker, "sell", bestAsk)
+	}
+
+	if !matched {
+		fmt.Printf("No matches found this iteration\n")
+	}
+}
+
+// setBidQueue stores the updated bid queue for a price level, clearing the level
+// (and its price from bidPrices) once the queue is drained
+func (ob *OrderBook) setBidQueue(price float64, queue []Order) {
+	if len(queue) == 0 {
+		delete(ob.Bids, price)
+		ob.bidPrices = removeBidPrice(ob.bidPrices, price)
+		return
+	}
+	ob.Bids[price] = queue
+}
+
+// setAskQueue stores the updated ask queue for a price level, clearing the level
+// (and its price from askPrices) once the queue is drained
+func (ob *OrderBook) setAskQueue(price float64, queue []Order) {
+	if len(queue) == 0 {
+		delete(ob.Asks, price)
+		ob.askPrices = removeAskPrice(ob.askPrices, price)
+		return
+	}
+	ob.Asks[price] = queue
+}
+
+// SubscribeTrades registers a new trade subscriber and returns a channel that
+// receives every trade emitted from this point on. Each call hands back an
+// independent channel, so multiple subscribers (e.g. the market-data Exchange
+// and a FIX session's fill pump) each see every trade instead of racing to
+// read off one shared channel. Callers must eventually call UnsubscribeTrades
+// to release it.
+func (ob *OrderBook) SubscribeTrades() chan Trade {
+	ob.tradeSubsMu.Lock()
+	defer ob.tradeSubsMu.Unlock()
+
+	ch := make(chan Trade, 1024)
+	ob.tradeSubs[ch] = struct{}{}
+	return ch
+}
+
+// UnsubscribeTrades removes and closes a trade subscription created by
+// SubscribeTrades
+func (ob *OrderBook) UnsubscribeTrades(ch chan Trade) {
+	ob.tradeSubsMu.Lock()
+	defer ob.tradeSubsMu.Unlock()
+
+	if _, ok := ob.tradeSubs[ch]; ok {
+		delete(ob.tradeSubs, ch)
+		close(ch)
+	}
+}
+
+// emitTrade broadcasts a trade to every registered subscriber without
+// blocking the matching loop
+func (ob *OrderBook) emitTrade(trade Trade) {
+	ob.tradeSubsMu.Lock()
+	defer ob.tradeSubsMu.Unlock()
+
+	for ch := range ob.tradeSubs {
+		select {
+		case ch <- trade:
+		default:
+			fmt.Printf("trade channel full, dropping trade for order ids %d and %d\n", trade.BuyOrderID, trade.SellOrderID)
+		}
+	}
+}
+
+// emitBookEvent sends a depth/quote update on the Events channel without
+// blocking the matching loop. Called with ob.mu already held, so the network
+// write that turns this into wire traffic always happens later, on the
+// consuming exchange's own goroutine.
+func (ob *OrderBook) emitBookEvent(ticker, side string, price float64) {
+	var size int
+	if side == "buy" {
+		size = aggregateSize(ob.Bids[price])
+	} else {
+		size = aggregateSize(ob.Asks[price])
+	}
+
+	var bestBid, bestAsk float64
+	var bestBidSize, bestAskSize int
+	if len(ob.bidPrices) > 0 {
+		bestBid = ob.bidPrices[0]
+		bestBidSize = aggregateSize(ob.Bids[bestBid])
+	}
+	if len(ob.askPrices) > 0 {
+		bestAsk = ob.askPrices[0]
+		bestAskSize = aggregateSize(ob.Asks[bestAsk])
+	}
+
+	event := BookEvent{
+		Ticker:      ticker,
+		Side:        side,
+		Price:       price,
+		Size:        size,
+		BestBid:     bestBid,
+		BestBidSize: bestBidSize,
+		BestAsk:     bestAsk,
+		BestAskSize: bestAskSize,
+	}
+
+	select {
+	case ob.Events <- event:
+	default:
+		fmt.Printf("event channel full, dropping depth update for %s %s %.2f\n", ticker, side, price)
+	}
+}
+
+// aggregateSize sums the resting size across every order in a price level's queue
+func aggregateSize(queue []Order) int {
+	total := 0
+	for _, order := range queue {
+		total += order.Size
+	}
+	return total
+}
+
+// DepthSnapshot returns the current resting size at every bid and ask price
+// level, best-first on each side, for a market-data subscriber resyncing after
+// a gap in the Events sequence
+func (ob *OrderBook) DepthSnapshot() (bids, asks []BookEvent) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	for _, price := range ob.bidPrices {
+		bids = append(bids, BookEvent{Side: "buy", Price: price, Size: aggregateSize(ob.Bids[price])})
+	}
+	for _, price := range ob.askPrices {
+		asks = append(asks, BookEvent{Side: "sell", Price: price, Size: aggregateSize(ob.Asks[price])})
+	}
+	return bids, asks
+}
+
+// StopMatching gracefully stops the order matching process
+func (ob *OrderBook) StopMatching() {
+	close(ob.stopChan) // Signal the matching (and snapshotting) goroutines to stop
+
+	ob.mu.Lock()         // Lock for thread safety
+	defer ob.mu.Unlock() // Ensure unlock on return
+
+	ob.pruneNonGTC()
+
+	if ob.journal != nil {
+		if err := ob.journal.Close(); err != nil {
+			fmt.Println("journal close error:", err)
+		}
+	}
+}
+
+// pruneNonGTC discards every resting order that isn't Good-Til-Cancelled. Used
+// both when matching stops and when reconstructing a book from its journal,
+// since only GTC orders are meant to survive past their original session.
+func (ob *OrderBook) pruneNonGTC() {
+	// Process Good-Til-Cancelled (GTC) orders in Bids
+	for price, bids := range ob.Bids {
+		newBids := []Order{}
+		for _, bid := range bids {
+			if bid.GTC { // Keep only GTC orders
+				newBids = append(newBids, bid)
+			}
+		}
+		if len(newBids) == 0 {
+			delete(ob.Bids, price) // Remove price level if no GTC orders remain
+			ob.bidPrices = removeBidPrice(ob.bidPrices, price)
+		} else {
+			ob.Bids[price] = newBids // Update with only GTC orders
+		}
+	}
+
+	// Process GTC orders in Asks
+	for price, asks := range ob.Asks {
+		newAsks := []Order{}
+		for _, ask := range asks {
+			if ask.GTC { // Keep only GTC orders
+				newAsks = append(newAsks, ask)
+			}
+		}
+		if len(newAsks) == 0 {
+			delete(ob.Asks, price) // Remove price level if no GTC orders remain
+			ob.askPrices = removeAskPrice(ob.askPrices, price)
+		} else {
+			ob.Asks[price] = newAsks // Update with only GTC orders
+		}
+	}
+}
+
+// AddOrder adds a new order to the order book and returns its assigned id
+func (ob *OrderBook) AddOrder(order Order) int {
+	ob.mu.Lock()         // Lock for thread safety
+	defer ob.mu.Unlock() // Ensure unlock on return
+
+	// Assign order ID and timestamp
+	order.Id = ob.nextOrderID
+	order.Timestamp = time.Now()
+	ob.nextOrderID++
+
+	// Set order flags based on type
+	if order.OrderType == 3 {
+		order.GTC = true // Good-Til-Cancelled
+	} else if order.OrderType == 4 {
+		order.FOK = true // Fill-or-Kill
+	}
+
+	// Add order to appropriate side's FIFO queue; appending under the lock is
+	// enough to preserve time priority, so no re-sort is needed
+	if order.Side == "buy" {
+		if _, exists := ob.Bids[order.Price]; !exists {
+			ob.bidPrices = insertBidPrice(ob.bidPrices, order.Price)
+		}
+		ob.Bids[order.Price] = append(ob.Bids[order.Price], order)
+	} else if order.Side == "sell" {
+		if _, exists := ob.Asks[order.Price]; !exists {
+			ob.askPrices = insertAskPrice(ob.askPrices, order.Price)
+		}
+		ob.Asks[order.Price] = append(ob.Asks[order.Price], order)
+	}
+
+	if ob.journal != nil {
+		if err := ob.journal.appendAddOrder(order); err != nil {
+			fmt.Println("journal append error:", err)
+		}
+	}
+
+	ob.emitBookEvent(order.Ticker, order.Side, order.Price)
+
+	return order.Id
+}
+
+// SubmitLimitOrder is a convenience wrapper around AddOrder for callers, such as
+// the execution package, that only need to place a plain limit order
+func (ob *OrderBook) SubmitLimitOrder(side, ticker string, price float64, size int, accountID string) int {
+	return ob.AddOrder(Order{
+		Price:     price,
+		Ticker:    ticker,
+		Size:      size,
+		OrderType: GetType("limit"),
+		Side:      side,
+		AccountID: accountID,
+	})
+}
+
+// CancelOrder removes a resting order from the book by id, searching both sides.
+// It reports whether an order was found and removed.
+func (ob *OrderBook) CancelOrder(id int) bool {
+	ob.mu.Lock()         // Lock for thread safety
+	defer ob.mu.Unlock() // Ensure unlock on return
+
+	removed, ok := ob.removeOrder(id)
+	if !ok {
+		return false
+	}
+
+	if ob.journal != nil {
+		if err := ob.journal.appendCancel(id); err != nil {
+			fmt.Println("journal append error:", err)
+		}
+	}
+
+	ob.emitBookEvent(removed.Ticker, removed.Side, removed.Price)
+
+	return true
+}
+
+// removeOrder deletes a resting order by id from whichever side holds it,
+// returning the removed order so callers can report what changed
+func (ob *OrderBook) removeOrder(id int) (Order, bool) {
+	for price, bids := range ob.Bids {
+		for i, bid := range bids {
+			if bid.Id == id {
+				ob.setBidQueue(price, append(bids[:i], bids[i+1:]...))
+				return bid, true
+			}
+		}
+	}
+
+	for price, asks := range ob.Asks {
+		for i, ask := range asks {
+			if ask.Id == id {
+				ob.setAskQueue(price, append(asks[:i], asks[i+1:]...))
+				return ask, true
+			}
+		}
+	}
+
+	return Order{}, false
+}
+
+// BestBid returns the highest resting bid price and whether one exists
+func (ob *OrderBook) BestBid() (float64, bool) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if len(ob.bidPrices) == 0 {
+		return 0, false
+	}
+	return ob.bidPrices[0], true
+}
+
+// BestAsk returns the lowest resting ask price and whether one exists
+func (ob *OrderBook) BestAsk() (float64, bool) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if len(ob.askPrices) == 0 {
+		return 0, false
+	}
+	return ob.askPrices[0], true
+}
+
+// OrderRemainingSize reports the remaining size of a still-resting order by id,
+// searching both sides. The second return value is false if the order is no
+// longer in the book (fully filled or already cancelled).
+func (ob *OrderBook) OrderRemainingSize(id int) (int, bool) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	for _, bids := range ob.Bids {
+		for _, bid := range bids {
+			if bid.Id == id {
+				return bid.Size, true
+			}
+		}
+	}
+
+	for _, asks := range ob.Asks {
+		for _, ask := range asks {
+			if ask.Id == id {
+				return ask.Size, true
+			}
+		}
+	}
+
+	return 0, false
+}
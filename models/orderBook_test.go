@@ -0,0 +1,162 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// waitForTrade blocks until a trade is emitted on ch or fails the test after
+// a generous timeout (matching runs on a 20ms tick). Callers subscribe ch via
+// ob.SubscribeTrades() before placing any orders, so no trade can fire before
+// the subscription exists.
+func waitForTrade(t *testing.T, ch chan Trade) Trade {
+	t.Helper()
+
+	select {
+	case trade := <-ch:
+		return trade
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("timed out waiting for trade")
+		return Trade{}
+	}
+}
+
+func TestMarketOrderFill(t *testing.T) {
+	ob := NewOrderBook()
+	defer ob.StopMatching()
+	ticker := "TEST"
+	ch := ob.SubscribeTrades()
+	defer ob.UnsubscribeTrades(ch)
+
+	ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 10, OrderType: GetType("market"), Side: "buy"})
+	ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 10, OrderType: GetType("market"), Side: "sell"})
+
+	trade := waitForTrade(t, ch)
+	if trade.Price != 100 || trade.Size != 10 {
+		t.Fatalf("unexpected trade: %+v", trade)
+	}
+
+	if _, ok := ob.BestBid(); ok {
+		t.Fatalf("expected no resting bid after a full fill")
+	}
+	if _, ok := ob.BestAsk(); ok {
+		t.Fatalf("expected no resting ask after a full fill")
+	}
+}
+
+func TestLimitOrderRestsWhenBookDoesNotCross(t *testing.T) {
+	ob := NewOrderBook()
+	defer ob.StopMatching()
+	ticker := "TEST"
+
+	ob.AddOrder(Order{Price: 99, Ticker: ticker, Size: 10, OrderType: GetType("limit"), Side: "buy"})
+	ob.AddOrder(Order{Price: 101, Ticker: ticker, Size: 10, OrderType: GetType("limit"), Side: "sell"})
+
+	time.Sleep(50 * time.Millisecond)
+
+	bid, ok := ob.BestBid()
+	if !ok || bid != 99 {
+		t.Fatalf("expected resting bid at 99, got %v (ok=%v)", bid, ok)
+	}
+	ask, ok := ob.BestAsk()
+	if !ok || ask != 101 {
+		t.Fatalf("expected resting ask at 101, got %v (ok=%v)", ask, ok)
+	}
+}
+
+func TestGTCOrderSurvivesStopMatching(t *testing.T) {
+	ob := NewOrderBook()
+	ticker := "TEST"
+
+	id := ob.AddOrder(Order{Price: 98, Ticker: ticker, Size: 5, OrderType: GetType("gtc"), Side: "buy"})
+
+	time.Sleep(50 * time.Millisecond)
+	ob.StopMatching()
+
+	remaining, ok := ob.OrderRemainingSize(id)
+	if !ok || remaining != 5 {
+		t.Fatalf("expected GTC order to survive StopMatching with size 5, got %v (ok=%v)", remaining, ok)
+	}
+}
+
+func TestNonGTCOrderPrunedOnStopMatching(t *testing.T) {
+	ob := NewOrderBook()
+	ticker := "TEST"
+
+	id := ob.AddOrder(Order{Price: 97, Ticker: ticker, Size: 5, OrderType: GetType("limit"), Side: "buy"})
+
+	time.Sleep(50 * time.Millisecond)
+	ob.StopMatching()
+
+	if _, ok := ob.OrderRemainingSize(id); ok {
+		t.Fatalf("expected non-GTC resting order to be pruned on StopMatching")
+	}
+}
+
+func TestFOKOrderCancelledWhenUnfillableInFull(t *testing.T) {
+	ob := NewOrderBook()
+	defer ob.StopMatching()
+	ticker := "TEST"
+
+	buyID := ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 5, OrderType: GetType("fok"), Side: "buy"})
+	sellID := ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 10, OrderType: GetType("fok"), Side: "sell"})
+
+	time.Sleep(50 * time.Millisecond)
+
+	remaining, ok := ob.OrderRemainingSize(buyID)
+	if !ok || remaining != 5 {
+		t.Fatalf("expected the matchable FOK buy to still be resting with size 5, got %v (ok=%v)", remaining, ok)
+	}
+	if _, ok := ob.OrderRemainingSize(sellID); ok {
+		t.Fatalf("expected the oversized FOK sell to be cancelled, not resting")
+	}
+}
+
+func TestFOKOrderFillsWhenSizesMatch(t *testing.T) {
+	ob := NewOrderBook()
+	defer ob.StopMatching()
+	ticker := "TEST"
+	ch := ob.SubscribeTrades()
+	defer ob.UnsubscribeTrades(ch)
+
+	buyID := ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 10, OrderType: GetType("fok"), Side: "buy"})
+	sellID := ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 10, OrderType: GetType("fok"), Side: "sell"})
+
+	trade := waitForTrade(t, ch)
+	if trade.BuyOrderID != buyID || trade.SellOrderID != sellID || trade.Size != 10 {
+		t.Fatalf("unexpected trade: %+v", trade)
+	}
+}
+
+func TestInterleavedPartialFillsPreserveTimePriority(t *testing.T) {
+	ob := NewOrderBook()
+	defer ob.StopMatching()
+	ticker := "TEST"
+	ch := ob.SubscribeTrades()
+	defer ob.UnsubscribeTrades(ch)
+
+	// Two resting bids at the same price, FIFO order: firstBid before secondBid
+	firstBid := ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 6, OrderType: GetType("limit"), Side: "buy"})
+	secondBid := ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 4, OrderType: GetType("limit"), Side: "buy"})
+
+	// One ask for 8 should fully fill firstBid, then partially fill secondBid
+	ob.AddOrder(Order{Price: 100, Ticker: ticker, Size: 8, OrderType: GetType("limit"), Side: "sell"})
+
+	first := waitForTrade(t, ch)
+	second := waitForTrade(t, ch)
+
+	if first.BuyOrderID != firstBid || first.Size != 6 {
+		t.Fatalf("expected first trade to fully fill firstBid for 6, got %+v", first)
+	}
+	if second.BuyOrderID != secondBid || second.Size != 2 {
+		t.Fatalf("expected second trade to partially fill secondBid for 2, got %+v", second)
+	}
+
+	remaining, ok := ob.OrderRemainingSize(secondBid)
+	if !ok || remaining != 2 {
+		t.Fatalf("expected secondBid to rest with remaining size 2, got %v (ok=%v)", remaining, ok)
+	}
+	if _, ok := ob.BestAsk(); ok {
+		t.Fatalf("expected the ask to be fully filled, not resting")
+	}
+}
@@ -1,165 +1,470 @@
-package models
-
-import (
-	"bufio"
-	"fmt"
-	"net"
-	"strconv"
-	"strings"
-	"sync"
-	"time"
-)
-
-// Server represents a trading server managing client connections and order books
-type Server struct {
-	listenAddr string                // Network address to listen on
-	ln         net.Listener          // Network listener for incoming connections
-	quitch     chan struct{}         // Channel to signal server shutdown
-	clients    map[net.Conn]string   // Map of client connections to their exchange names
-	exchanges  map[string]*OrderBook // Map of exchange names to their order books
-	mu         sync.Mutex            // Mutex for thread-safe operations
-}
-
-// NewServer creates and initializes a new Server instance
-func NewServer(listenAddr string) *Server {
-	return &Server{
-		listenAddr: listenAddr,                  // Set listening address
-		quitch:     make(chan struct{}),         // Initialize quit channel
-		clients:    make(map[net.Conn]string),   // Initialize clients map
-		exchanges:  make(map[string]*OrderBook), // Initialize exchanges map
-	}
-}
-
-// StartServer begins the server's operation
-func (s *Server) StartServer() error {
-	ln, err := net.Listen("tcp", s.listenAddr) // Start TCP listener
-	if err != nil {
-		return err // Return error if listening fails
-	}
-	defer ln.Close() // Ensure listener closes when function returns
-
-	s.ln = ln // Store listener in server struct
-
-	go s.acceptLoop() // Start accepting connections in separate goroutine
-
-	<-s.quitch // Wait for quit signal
-
-	return nil // Return nil on successful shutdown
-}
-
-// acceptLoop continuously accepts new client connections
-func (s *Server) acceptLoop() {
-	for {
-		conn, err := s.ln.Accept() // Accept incoming connection
-		if err != nil {
-			fmt.Println("accept error:", err) // Log acceptance errors
-			continue
-		}
-
-		go s.handleClient(conn) // Handle each client in a separate goroutine
-	}
-}
-
-// handleClient manages communication with a connected client
-func (s *Server) handleClient(conn net.Conn) {
-	defer conn.Close() // Ensure connection closes when function returns
-
-	fmt.Println("new connection to server:", conn.RemoteAddr()) // Log new connection
-
-	reader := bufio.NewReader(conn) // Create buffered reader for client input
-	for {
-		msg, err := reader.ReadString('\n') // Read message until newline
-		if err != nil {
-			fmt.Println("read error:", err) // Log read errors
-			return
-		}
-
-		msg = strings.TrimSpace(msg) // Remove leading/trailing whitespace
-		// Handle JOIN command
-		if strings.HasPrefix(msg, "JOIN ") {
-			exchangeName := strings.TrimPrefix(msg, "JOIN ")
-			s.joinExchange(exchangeName, conn)
-
-			// Handle LEAVE command
-		} else if strings.HasPrefix(msg, "LEAVE ") {
-			exchangeName := strings.TrimPrefix(msg, "LEAVE ")
-			s.leaveExchange(exchangeName, conn)
-
-			// Handle TRADE command
-		} else if strings.HasPrefix(msg, "TRADE ") {
-			parts := strings.SplitN(msg, " ", 7) // Split into max 7 parts
-			if len(parts) != 7 {
-				conn.Write([]byte("Invalid message format\n")) // Validate message format
-				continue
-			}
-
-			exchangeName := parts[1]
-			order := orderFromParts(parts) // Parse order from message parts
-
-			s.trade(exchangeName, order, conn) // Process trade
-
-		} else {
-			conn.Write([]byte("Unknown command\n")) // Handle unknown commands
-			continue
-		}
-	}
-}
-
-// orderFromParts constructs an Order from message parts
-func orderFromParts(parts []string) *Order {
-	side := parts[2]                             // Buy or sell
-	orderType := parts[3]                        // Type of order
-	price, _ := strconv.ParseFloat(parts[4], 64) // Parse price (ignoring error)
-	size, _ := strconv.Atoi(parts[5])            // Parse size (ignoring error)
-	ticker := parts[6]                           // Trading pair/symbol
-
-	return &Order{
-		Price:     price,              // Set order price
-		Ticker:    ticker,             // Set trading ticker
-		Size:      size,               // Set order size
-		OrderType: GetType(orderType), // Convert string to order type
-		Side:      side,               // Set buy/sell side
-		Timestamp: time.Now(),         // Set current timestamp
-	}
-}
-
-// joinExchange adds a client to an exchange
-func (s *Server) joinExchange(exchangeName string, conn net.Conn) {
-	s.mu.Lock()         // Lock for thread safety
-	defer s.mu.Unlock() // Ensure unlock on return
-
-	// Create new order book if exchange doesn't exist
-	if _, exists := s.exchanges[exchangeName]; !exists {
-		s.exchanges[exchangeName] = NewOrderBook()
-	}
-	s.clients[conn] = exchangeName                                         // Associate client with exchange
-	conn.Write([]byte(fmt.Sprintf("Joined exchange: %s\n", exchangeName))) // Confirm join
-}
-
-// leaveExchange removes a client from an exchange
-func (s *Server) leaveExchange(exchangeName string, conn net.Conn) {
-	s.mu.Lock()         // Lock for thread safety
-	defer s.mu.Unlock() // Ensure unlock on return
-
-	// Note: Current implementation doesn't check if client was in the exchange
-	if _, exists := s.exchanges[exchangeName]; !exists {
-		conn.Write([]byte(fmt.Sprintf("Left exchange: %s\n", exchangeName)))
-	}
-	delete(s.clients, conn)                                              // Remove client from clients map
-	conn.Write([]byte(fmt.Sprintf("Left exchange: %s\n", exchangeName))) // Confirm leave
-}
-
-// trade processes a trading order in an exchange
-func (s *Server) trade(exchangeName string, order *Order, conn net.Conn) {
-	s.mu.Lock()         // Lock for thread safety
-	defer s.mu.Unlock() // Ensure unlock on return
-
-	exchange, exists := s.exchanges[exchangeName] // Get exchange
-	if !exists {
-		conn.Write([]byte(fmt.Sprintf("Not in exchange %s\n", exchangeName))) // Check existence
-		return
-	}
-
-	exchange.AddOrder(*order)                                                     // Add order to exchange's order book
-	conn.Write([]byte(fmt.Sprintf("Order added to %s exchange\n", exchangeName))) // Confirm trade
-}
+package models
+
+import (
+	"TCP-Exchange/execution"
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Rate limits applied to every connection's message reads and, once logged
+// on, to each account's order submissions
+const (
+	connReadRate  = rate.Limit(50) // messages per second allowed per connection
+	connReadBurst = 100
+	orderRate     = rate.Limit(20) // order submissions per second allowed per account
+	orderBurst    = 40
+)
+
+// Server represents a trading server managing client connections and order books
+type Server struct {
+	listenAddr       string                   // Network address to listen on
+	ln               net.Listener             // Network listener for incoming connections
+	quitch           chan struct{}            // Channel to signal server shutdown
+	clients          map[net.Conn]string      // Map of client connections to their exchange names
+	exchanges        map[string]*OrderBook    // Map of exchange names to their order books
+	marketData       map[string]*Exchange     // Map of exchange names to their market-data broadcaster
+	journalDir       string                   // Directory to journal each exchange's orders/trades in, empty disables journaling
+	snapshotInterval time.Duration            // How often each exchange journals a full book snapshot
+	accounts         AccountStore             // apiKey -> secret, loaded from the accounts config file
+	orderLimiters    map[string]*rate.Limiter // Per-account token-bucket limiter for order submissions
+	mu               sync.Mutex               // Mutex for thread-safe operations
+}
+
+// NewServer creates and initializes a new Server instance. journalDir enables
+// crash recovery by journaling every exchange's orders, cancels and fills to
+// "<journalDir>/<exchange>.journal"; pass "" to run purely in-memory.
+// snapshotInterval controls how often a full book snapshot is journaled so
+// recovery doesn't need to replay the entire history; it is ignored when
+// journalDir is "". accountsPath loads the apiKey/secret pairs LOGON
+// handshakes are verified against; pass "" to run with no registered
+// accounts, in which case every LOGON fails.
+func NewServer(listenAddr, journalDir string, snapshotInterval time.Duration, accountsPath string) *Server {
+	accounts := AccountStore{}
+	if accountsPath != "" {
+		loaded, err := LoadAccountStore(accountsPath)
+		if err != nil {
+			fmt.Println("failed to load account store:", err)
+		} else {
+			accounts = loaded
+		}
+	}
+
+	return &Server{
+		listenAddr:       listenAddr,                  // Set listening address
+		quitch:           make(chan struct{}),         // Initialize quit channel
+		clients:          make(map[net.Conn]string),   // Initialize clients map
+		exchanges:        make(map[string]*OrderBook), // Initialize exchanges map
+		marketData:       make(map[string]*Exchange),  // Initialize market-data map
+		journalDir:       journalDir,
+		snapshotInterval: snapshotInterval,
+		accounts:         accounts,
+		orderLimiters:    make(map[string]*rate.Limiter), // Initialize per-account order limiters map
+	}
+}
+
+// StartServer begins the server's operation
+func (s *Server) StartServer() error {
+	ln, err := net.Listen("tcp", s.listenAddr) // Start TCP listener
+	if err != nil {
+		return err // Return error if listening fails
+	}
+	defer ln.Close() // Ensure listener closes when function returns
+
+	s.ln = ln // Store listener in server struct
+
+	go s.acceptLoop() // Start accepting connections in separate goroutine
+
+	<-s.quitch // Wait for quit signal
+
+	return nil // Return nil on successful shutdown
+}
+
+// acceptLoop continuously accepts new client connections
+func (s *Server) acceptLoop() {
+	for {
+		conn, err := s.ln.Accept() // Accept incoming connection
+		if err != nil {
+			fmt.Println("accept error:", err) // Log acceptance errors
+			continue
+		}
+
+		go s.handleClient(conn) // Handle each client in a separate goroutine
+	}
+}
+
+// clientSession tracks the per-connection state a LOGON handshake establishes:
+// which account (if any) is authenticated on this connection, the
+// token-bucket limiting how fast it may send messages, and any TWAP executors
+// it has started, so they can be stopped if the connection goes away before
+// they finish.
+type clientSession struct {
+	accountID   string
+	readLimiter *rate.Limiter
+	twapOrders  []*execution.TWAPOrder
+}
+
+// handleClient manages communication with a connected client
+func (s *Server) handleClient(conn net.Conn) {
+	defer conn.Close()           // Ensure connection closes when function returns
+	defer s.unsubscribeAll(conn) // Drop any market-data subscriptions this client held
+
+	fmt.Println("new connection to server:", conn.RemoteAddr()) // Log new connection
+
+	session := &clientSession{readLimiter: rate.NewLimiter(connReadRate, connReadBurst)}
+	defer s.stopTWAPOrders(session) // Stop any TWAP executors this client abandoned by disconnecting
+	reader := bufio.NewReader(conn) // Create buffered reader for client input
+	for {
+		msg, err := reader.ReadString('\n') // Read message until newline
+		if err != nil {
+			fmt.Println("read error:", err) // Log read errors
+			return
+		}
+
+		if !session.readLimiter.Allow() {
+			conn.Write([]byte("ERROR connection rate limit exceeded\n"))
+			continue
+		}
+
+		msg = strings.TrimSpace(msg) // Remove leading/trailing whitespace
+		// Handle LOGON command
+		if strings.HasPrefix(msg, "LOGON ") {
+			parts := strings.Fields(msg)
+			if len(parts) != 4 {
+				conn.Write([]byte("Invalid LOGON format\n")) // Validate message format
+				continue
+			}
+			s.logon(parts[1], parts[2], parts[3], session, conn)
+
+			// Handle JOIN command
+		} else if strings.HasPrefix(msg, "JOIN ") {
+			exchangeName := strings.TrimPrefix(msg, "JOIN ")
+			s.joinExchange(exchangeName, conn)
+
+			// Handle LEAVE command
+		} else if strings.HasPrefix(msg, "LEAVE ") {
+			exchangeName := strings.TrimPrefix(msg, "LEAVE ")
+			s.leaveExchange(exchangeName, conn)
+
+			// Handle TRADE command
+		} else if strings.HasPrefix(msg, "TRADE ") {
+			if !s.authorizeOrder(session, conn) {
+				continue
+			}
+
+			parts := strings.SplitN(msg, " ", 7) // Split into max 7 parts
+			if len(parts) != 7 {
+				conn.Write([]byte("Invalid message format\n")) // Validate message format
+				continue
+			}
+
+			exchangeName := parts[1]
+			order := orderFromParts(parts)      // Parse order from message parts
+			order.AccountID = session.accountID // Attribute the order to the logged-on account
+
+			s.trade(exchangeName, order, conn) // Process trade
+
+			// Handle TWAP command
+		} else if strings.HasPrefix(msg, "TWAP ") {
+			if !s.authorizeOrder(session, conn) {
+				continue
+			}
+
+			parts := strings.Fields(msg) // Split on whitespace; stopPrice is optional
+			if len(parts) != 8 && len(parts) != 9 {
+				conn.Write([]byte("Invalid TWAP format\n")) // Validate message format
+				continue
+			}
+
+			s.startTWAP(parts, session, conn) // Parse and launch the TWAP executor
+
+			// Handle protocol upgrade to FIX 4.4 framing. Requires a prior
+			// successful LOGON on this same connection, the same as TRADE/TWAP,
+			// so FIX orders are rate-limited and attributed to an account too.
+		} else if strings.HasPrefix(msg, "PROTO FIX ") {
+			if session.accountID == "" {
+				conn.Write([]byte("ERROR not authenticated, send LOGON first\n"))
+				continue
+			}
+			exchangeName := strings.TrimPrefix(msg, "PROTO FIX ")
+			s.handleFIXSession(exchangeName, conn, reader, session.accountID, session.readLimiter) // Takes over the connection until Logout
+			return
+
+			// Handle RECOVER admin command
+		} else if strings.HasPrefix(msg, "RECOVER ") {
+			exchangeName := strings.TrimPrefix(msg, "RECOVER ")
+			s.recover(exchangeName, conn)
+
+			// Handle SUBSCRIBE command for market data
+		} else if strings.HasPrefix(msg, "SUBSCRIBE ") {
+			parts := strings.Fields(msg)
+			if len(parts) != 3 {
+				conn.Write([]byte("Invalid SUBSCRIBE format\n")) // Validate message format
+				continue
+			}
+			s.subscribe(parts[1], parts[2], conn)
+
+			// Handle SNAPSHOT command for market-data resync
+		} else if strings.HasPrefix(msg, "SNAPSHOT ") {
+			exchangeName := strings.TrimPrefix(msg, "SNAPSHOT ")
+			s.snapshot(exchangeName, conn)
+
+		} else {
+			conn.Write([]byte("Unknown command\n")) // Handle unknown commands
+			continue
+		}
+	}
+}
+
+// logon verifies a LOGON handshake and, on success, authenticates session for
+// the rest of the connection's lifetime
+func (s *Server) logon(apiKey, hmacHex, nonce string, session *clientSession, conn net.Conn) {
+	accountID, ok := s.authenticate(apiKey, nonce, hmacHex)
+	if !ok {
+		conn.Write([]byte("ERROR authentication failed\n"))
+		return
+	}
+	session.accountID = accountID
+	conn.Write([]byte(fmt.Sprintf("Logged on as %s\n", accountID)))
+}
+
+// authorizeOrder checks that session is authenticated and hasn't exceeded its
+// account's order-submission rate limit, writing an explicit error frame and
+// reporting false if either check fails
+func (s *Server) authorizeOrder(session *clientSession, conn net.Conn) bool {
+	if session.accountID == "" {
+		conn.Write([]byte("ERROR not authenticated, send LOGON first\n"))
+		return false
+	}
+
+	if !s.getOrCreateOrderLimiter(session.accountID).Allow() {
+		conn.Write([]byte("ERROR order rate limit exceeded\n"))
+		return false
+	}
+
+	return true
+}
+
+// getOrCreateOrderLimiter returns accountID's order-submission limiter,
+// creating it on first use
+func (s *Server) getOrCreateOrderLimiter(accountID string) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limiter, exists := s.orderLimiters[accountID]
+	if !exists {
+		limiter = rate.NewLimiter(orderRate, orderBurst)
+		s.orderLimiters[accountID] = limiter
+	}
+	return limiter
+}
+
+// orderFromParts constructs an Order from message parts
+func orderFromParts(parts []string) *Order {
+	side := parts[2]                             // Buy or sell
+	orderType := parts[3]                        // Type of order
+	price, _ := strconv.ParseFloat(parts[4], 64) // Parse price (ignoring error)
+	size, _ := strconv.Atoi(parts[5])            // Parse size (ignoring error)
+	ticker := parts[6]                           // Trading pair/symbol
+
+	return &Order{
+		Price:     price,              // Set order price
+		Ticker:    ticker,             // Set trading ticker
+		Size:      size,               // Set order size
+		OrderType: GetType(orderType), // Convert string to order type
+		Side:      side,               // Set buy/sell side
+		Timestamp: time.Now(),         // Set current timestamp
+	}
+}
+
+// startTWAP parses a TWAP command's fields and starts its executor against the
+// named exchange, reporting slice fills back on conn until it finishes. Child
+// orders are submitted under session's account, and the executor is tracked
+// on session so it can be stopped if the connection disconnects early.
+func (s *Server) startTWAP(parts []string, session *clientSession, conn net.Conn) {
+	accountID := session.accountID
+	exchangeName := parts[1]
+	side := parts[2]
+	ticker := parts[3]
+	totalSize, _ := strconv.Atoi(parts[4])                // Parse total size (ignoring error)
+	sliceSize, _ := strconv.Atoi(parts[5])                // Parse slice size (ignoring error)
+	intervalMs, _ := strconv.Atoi(parts[6])               // Parse slice interval (ignoring error)
+	deadlineUnix, _ := strconv.ParseInt(parts[7], 10, 64) // Parse deadline (ignoring error)
+	deadline := time.Unix(deadlineUnix, 0)
+
+	if totalSize <= 0 || sliceSize <= 0 || intervalMs <= 0 {
+		conn.Write([]byte("Invalid TWAP format: totalSize, sliceSize and intervalMs must be positive\n"))
+		return
+	}
+
+	var stopPrice float64
+	hasStop := len(parts) == 9
+	if hasStop {
+		stopPrice, _ = strconv.ParseFloat(parts[8], 64) // Parse stop price (ignoring error)
+	}
+
+	s.mu.Lock()
+	book, exists := s.exchanges[exchangeName]
+	s.mu.Unlock()
+	if !exists {
+		conn.Write([]byte(fmt.Sprintf("Not in exchange %s\n", exchangeName)))
+		return
+	}
+
+	twapOrder := execution.NewTWAPOrder(book, side, ticker, totalSize, sliceSize, intervalMs, deadline, stopPrice, hasStop, accountID, s.getOrCreateOrderLimiter(accountID))
+	session.twapOrders = append(session.twapOrders, twapOrder)
+	twapOrder.Start()
+
+	go func() {
+		for fill := range twapOrder.Fills {
+			conn.Write([]byte(fmt.Sprintf("TWAP slice fill: price %.2f size %d\n", fill.Price, fill.Size)))
+		}
+		conn.Write([]byte(fmt.Sprintf("TWAP order on %s complete\n", exchangeName)))
+	}()
+
+	conn.Write([]byte(fmt.Sprintf("TWAP order started on %s exchange\n", exchangeName)))
+}
+
+// getOrCreateExchange returns the named exchange's order book, creating it
+// (and its journal, if journaling is enabled) on first use. Callers must hold
+// s.mu.
+func (s *Server) getOrCreateExchange(exchangeName string) (*OrderBook, error) {
+	if book, exists := s.exchanges[exchangeName]; exists {
+		return book, nil
+	}
+
+	var book *OrderBook
+	if s.journalDir == "" {
+		book = NewOrderBook()
+	} else {
+		journalPath := filepath.Join(s.journalDir, exchangeName+".journal")
+		var err error
+		book, err = NewOrderBookWithJournal(journalPath, s.snapshotInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.exchanges[exchangeName] = book
+
+	marketData := NewExchange(exchangeName, book)
+	marketData.Start()
+	s.marketData[exchangeName] = marketData
+
+	return book, nil
+}
+
+// joinExchange adds a client to an exchange
+func (s *Server) joinExchange(exchangeName string, conn net.Conn) {
+	s.mu.Lock()         // Lock for thread safety
+	defer s.mu.Unlock() // Ensure unlock on return
+
+	if _, err := s.getOrCreateExchange(exchangeName); err != nil {
+		conn.Write([]byte(fmt.Sprintf("Failed to open exchange %s: %v\n", exchangeName, err)))
+		return
+	}
+	s.clients[conn] = exchangeName                                         // Associate client with exchange
+	conn.Write([]byte(fmt.Sprintf("Joined exchange: %s\n", exchangeName))) // Confirm join
+}
+
+// recover reports the journal recovery stats for an exchange: the last
+// sequence number and how many records were replayed when it was opened
+func (s *Server) recover(exchangeName string, conn net.Conn) {
+	s.mu.Lock()
+	book, exists := s.exchanges[exchangeName]
+	s.mu.Unlock()
+	if !exists {
+		conn.Write([]byte(fmt.Sprintf("Not in exchange %s\n", exchangeName)))
+		return
+	}
+
+	lastSeq, records := book.RecoveryStats()
+	conn.Write([]byte(fmt.Sprintf("Recovered exchange %s: last sequence %d, %d records replayed\n", exchangeName, lastSeq, records)))
+}
+
+// subscribe adds a client to one of an exchange's market-data channels
+// (bbo, depth, trades or bookSnapshot), creating the exchange on first use
+func (s *Server) subscribe(exchangeName, channel string, conn net.Conn) {
+	s.mu.Lock()
+	_, err := s.getOrCreateExchange(exchangeName)
+	marketData := s.marketData[exchangeName]
+	s.mu.Unlock()
+	if err != nil {
+		conn.Write([]byte(fmt.Sprintf("Failed to open exchange %s: %v\n", exchangeName, err)))
+		return
+	}
+
+	if !marketData.Subscribe(conn, channel) {
+		conn.Write([]byte(fmt.Sprintf("Unknown channel %s\n", channel)))
+		return
+	}
+	conn.Write([]byte(fmt.Sprintf("Subscribed to %s on %s\n", channel, exchangeName)))
+}
+
+// snapshot reports an exchange's full current depth so a market-data
+// subscriber can resync after a gap in the live DEPTH/BBO sequence
+func (s *Server) snapshot(exchangeName string, conn net.Conn) {
+	s.mu.Lock()
+	marketData, exists := s.marketData[exchangeName]
+	s.mu.Unlock()
+	if !exists {
+		conn.Write([]byte(fmt.Sprintf("Not in exchange %s\n", exchangeName)))
+		return
+	}
+
+	conn.Write([]byte(marketData.Snapshot()))
+}
+
+// unsubscribeAll drops conn from every exchange's market-data channels when
+// its connection closes
+func (s *Server) unsubscribeAll(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, marketData := range s.marketData {
+		marketData.Unsubscribe(conn)
+	}
+}
+
+// stopTWAPOrders stops every TWAP executor session started, so one left
+// running against a dead connection doesn't keep slicing into the book until
+// its deadline
+func (s *Server) stopTWAPOrders(session *clientSession) {
+	for _, twapOrder := range session.twapOrders {
+		twapOrder.Stop()
+	}
+}
+
+// leaveExchange removes a client from an exchange
+func (s *Server) leaveExchange(exchangeName string, conn net.Conn) {
+	s.mu.Lock()         // Lock for thread safety
+	defer s.mu.Unlock() // Ensure unlock on return
+
+	// Note: Current implementation doesn't check if client was in the exchange
+	if _, exists := s.exchanges[exchangeName]; !exists {
+		conn.Write([]byte(fmt.Sprintf("Left exchange: %s\n", exchangeName)))
+	}
+	delete(s.clients, conn)                                              // Remove client from clients map
+	conn.Write([]byte(fmt.Sprintf("Left exchange: %s\n", exchangeName))) // Confirm leave
+}
+
+// trade processes a trading order in an exchange
+func (s *Server) trade(exchangeName string, order *Order, conn net.Conn) {
+	s.mu.Lock()         // Lock for thread safety
+	defer s.mu.Unlock() // Ensure unlock on return
+
+	exchange, exists := s.exchanges[exchangeName] // Get exchange
+	if !exists {
+		conn.Write([]byte(fmt.Sprintf("Not in exchange %s\n", exchangeName))) // Check existence
+		return
+	}
+
+	exchange.AddOrder(*order)                                                     // Add order to exchange's order book
+	conn.Write([]byte(fmt.Sprintf("Order added to %s exchange\n", exchangeName))) // Confirm trade
+}